@@ -13,3 +13,16 @@ type Buffer interface {
 	io.ByteScanner // io.ByteReader
 	io.ByteWriter
 }
+
+// RuneBuffer is the interface that groups Buffer with read/write operations on
+// UTF-8 encoded text.
+type RuneBuffer interface {
+	Buffer
+
+	io.RuneScanner // io.RuneReader
+	io.StringWriter
+
+	// WriteRune appends the UTF-8 encoding of r and returns the number of bytes
+	// written.
+	WriteRune(r rune) (n int, err error)
+}
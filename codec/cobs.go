@@ -0,0 +1,171 @@
+package codec
+
+import (
+	"io"
+
+	"github.com/ardnew/nogc"
+)
+
+// cobsBlock is the maximum number of non-zero bytes in a COBS block.
+const cobsBlock = 0xFE
+
+// COBSEncoder encodes frames using Consistent Overhead Byte Stuffing (COBS),
+// with each frame terminated by a zero byte.
+//
+// Bytes of a frame are buffered internally until a block of up to 254 bytes is
+// complete, since each block is preceded by its length.
+type COBSEncoder struct {
+	out  output
+	blk  [cobsBlock]byte
+	k    int  // number of bytes in blk
+	open bool // frame in progress
+}
+
+// Encode encodes p as the next bytes of the current frame, writes any complete
+// encoded blocks to dst, and returns the number of bytes of p that were
+// encoded. The first call to Encode after End begins a new frame.
+//
+// If dst returns an error, such as ErrWriteOverflow from a full List, Encode
+// returns the number of bytes of p encoded so far and that error. Any encoded
+// bytes not yet written are retained and written first by the next call to
+// Encode or End, which continues the same frame with p[n:].
+func (e *COBSEncoder) Encode(dst io.ByteWriter, p []byte) (n int, err error) {
+	if e == nil {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if dst == nil || p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	if err = e.out.flush(dst); err != nil {
+		return 0, err
+	}
+	e.open = true
+	for _, c := range p {
+		if c != 0 {
+			e.blk[e.k] = c
+			e.k++
+		}
+		if c == 0 || e.k == cobsBlock {
+			e.block()
+		}
+		n++
+		if err = e.out.flush(dst); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// End ends the current frame and writes any remaining encoded bytes to dst.
+//
+// If dst returns an error, End returns that error, and the remaining bytes are
+// written by the next call to Encode or End. If encoded bytes retained by Encode
+// could not all be written first, the frame is not yet ended, and End must be
+// called again to end it.
+func (e *COBSEncoder) End(dst io.ByteWriter) (err error) {
+	if e == nil {
+		return &nogc.ErrInvalidReceiver
+	}
+	if dst == nil {
+		return &nogc.ErrInvalidArgument
+	}
+	if err = e.out.flush(dst); err != nil {
+		return err
+	}
+	if e.open {
+		e.block()
+		e.out.put(0)
+		e.open = false
+	}
+	return e.out.flush(dst)
+}
+
+// block stages the length and bytes of the current block to be written.
+func (e *COBSEncoder) block() {
+	e.out.put(byte(e.k + 1))
+	for _, c := range e.blk[:e.k] {
+		e.out.put(c)
+	}
+	e.k = 0
+}
+
+// Reset discards any frame in progress and any encoded bytes not yet written.
+func (e *COBSEncoder) Reset() {
+	if e == nil {
+		return
+	}
+	*e = COBSEncoder{}
+}
+
+// COBSDecoder decodes frames encoded using Consistent Overhead Byte Stuffing
+// (COBS), with each frame terminated by a zero byte.
+type COBSDecoder struct {
+	in   input
+	rem  int  // number of bytes remaining in the current block
+	zero bool // a zero byte follows the current block, unless it ends the frame
+}
+
+// Decode reads bytes from src until a complete frame has been decoded into p,
+// and returns the length of the frame. An empty frame, encoded as a single
+// block of length 1, is returned with length 0, but zero bytes between frames
+// are ignored.
+//
+// If src returns an error, such as ErrEmpty from an empty List, Decode returns
+// 0 and that error. The partial frame is retained in p, and the next call to
+// Decode, which must be given the same p, continues decoding the same frame.
+//
+// If a frame ends in the middle of a block, Decode returns ErrInvalidFrame, and
+// if the frame is longer than p, Decode returns io.ErrShortBuffer. In both
+// cases, the rest of the frame is discarded, and the next call to Decode
+// resynchronizes by decoding the frame following the next zero byte.
+func (d *COBSDecoder) Decode(src io.ByteReader, p []byte) (n int, err error) {
+	if d == nil {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if src == nil || p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	for {
+		c, err := src.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if c == 0 {
+			in, rem, zero := d.in, d.rem, d.zero
+			d.Reset()
+			switch {
+			case in.sync || in.n == 0 && rem == 0 && !zero:
+				continue
+			case rem > 0:
+				return 0, &nogc.ErrInvalidFrame
+			}
+			return in.n, nil
+		}
+		switch {
+		case d.in.sync:
+			continue
+		case d.rem > 0:
+			d.rem--
+		default:
+			// c is the length of the next block. The zero byte implied by the
+			// previous block is only decoded now that the frame has not ended.
+			zero := d.zero
+			d.rem, d.zero = int(c)-1, c <= cobsBlock
+			if !zero {
+				continue
+			}
+			c = 0
+		}
+		if err = d.in.decode(p, c); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Reset discards any frame in progress.
+func (d *COBSDecoder) Reset() {
+	if d == nil {
+		return
+	}
+	*d = COBSDecoder{}
+}
@@ -0,0 +1,98 @@
+package codec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ardnew/nogc"
+	seq "github.com/ardnew/nogc/fifo"
+)
+
+func TestCOBSEncoder_Encode(t *testing.T) {
+	tests := []struct {
+		name string
+		p    string
+		want string
+	}{
+		{name: "plain", p: "abc", want: "\x04abc\x00"},
+		{name: "zeros", p: "\x00a\x00", want: "\x01\x02a\x01\x00"},
+		{name: "empty", p: "", want: "\x01\x00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l seq.List
+			l.Configure(make([]byte, 16))
+			var e COBSEncoder
+			if n, err := e.Encode(&l, []byte(tt.p)); n != len(tt.p) || err != nil {
+				t.Fatalf("COBSEncoder.Encode() = %v, %v, want %v, %v", n, err, len(tt.p), nil)
+			}
+			if err := e.End(&l); err != nil {
+				t.Fatalf("COBSEncoder.End() error = %v", err)
+			}
+			got := make([]byte, 16)
+			n, _ := l.Read(got)
+			if string(got[:n]) != tt.want {
+				t.Errorf("COBSEncoder.Encode() = %q, want %q", got[:n], tt.want)
+			}
+		})
+	}
+}
+
+func TestCOBSDecoder_Decode(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{name: "frames", src: "\x03ab\x00\x00\x01\x00\x02c\x01\x00", want: []string{"ab", "", "c\x00"}},
+		{name: "zeros", src: "\x01\x01\x01\x00", want: []string{"\x00\x00"}},
+		{name: "empty", src: "\x00\x01\x00\x00\x02a\x00", want: []string{"", "a"}},
+		{name: "truncated", src: "\x04ab\x00\x02d\x00", want: []string{"invalid frame", "d"}},
+		{name: "too-long", src: "\x06abcde\x00\x03fg\x00", want: []string{"short buffer", "fg"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d COBSDecoder
+			got := testDecode(&d, tt.src, make([]byte, 4))
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("COBSDecoder.Decode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCOBSEncoder_End(t *testing.T) {
+	var l seq.List
+	l.Configure(make([]byte, 10))
+	var e COBSEncoder
+	p := bytes.Repeat([]byte{1}, 254)
+	if _, err := e.Encode(&l, p); err != &nogc.ErrWriteOverflow {
+		t.Fatalf("COBSEncoder.Encode() error = %v, want %v", err, &nogc.ErrWriteOverflow)
+	}
+	var got []byte
+	for {
+		err := e.End(&l)
+		if err != nil && err != &nogc.ErrWriteOverflow {
+			t.Fatalf("COBSEncoder.End() error = %v", err)
+		}
+		r := make([]byte, 10)
+		n, _ := l.Read(r)
+		got = append(got, r[:n]...)
+		if err == nil {
+			break
+		}
+	}
+	want := append(append([]byte{0xFF}, p...), 0x01, 0x00)
+	if !bytes.Equal(got, want) {
+		t.Errorf("COBSEncoder.End() = %x, want %x", got, want)
+	}
+}
+
+func TestCOBS_RoundTrip(t *testing.T) {
+	testRoundTrip(t, &COBSEncoder{}, &COBSDecoder{}, true)
+}
+
+func TestCOBS_Allocs(t *testing.T) {
+	testAllocs(t, &COBSEncoder{}, &COBSDecoder{})
+}
@@ -0,0 +1,52 @@
+package codec
+
+import "io"
+
+// output holds encoded bytes that have not yet been written to a destination.
+//
+// Encoders stage the bytes encoded from each input byte in output and then
+// flush them, so that encoding can be resumed after the destination is full
+// without losing part of an escape sequence or block.
+type output struct {
+	Byte [256]byte
+	lo   int
+	hi   int
+}
+
+// put stages c to be written by the next call to flush.
+func (o *output) put(c byte) {
+	o.Byte[o.hi] = c
+	o.hi++
+}
+
+// flush writes all staged bytes to w. If w returns an error, the bytes not yet
+// written remain staged and flush returns that error.
+func (o *output) flush(w io.ByteWriter) (err error) {
+	for o.lo < o.hi {
+		if err = w.WriteByte(o.Byte[o.lo]); err != nil {
+			return err
+		}
+		o.lo++
+	}
+	o.lo = 0
+	o.hi = 0
+	return nil
+}
+
+// input holds the state of a frame that has been partially decoded.
+type input struct {
+	n    int  // number of bytes decoded into the current frame
+	sync bool // discarding bytes until the next frame delimiter
+}
+
+// decode appends c to the current frame in p. If p is full, the rest of the
+// frame is discarded and decode returns io.ErrShortBuffer.
+func (in *input) decode(p []byte, c byte) (err error) {
+	if in.n >= len(p) {
+		in.sync = true
+		return io.ErrShortBuffer
+	}
+	p[in.n] = c
+	in.n++
+	return nil
+}
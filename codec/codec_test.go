@@ -0,0 +1,126 @@
+package codec
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ardnew/nogc"
+	seq "github.com/ardnew/nogc/fifo"
+)
+
+type encoder interface {
+	Encode(dst io.ByteWriter, p []byte) (n int, err error)
+	End(dst io.ByteWriter) (err error)
+}
+
+type decoder interface {
+	Decode(src io.ByteReader, p []byte) (n int, err error)
+}
+
+// frames returns payloads containing every special byte of each codec, empty
+// payloads, and payloads longer than a COBS block.
+func frames() [][]byte {
+	return [][]byte{
+		[]byte("abc"),
+		{},
+		{0x00},
+		{0xC0, 0xDB, 0xDC, 0xDD, 0x7E, 0x7D, 0x5E, 0x5D, 0x00, 0x00},
+		bytes.Repeat([]byte{0x11}, 254),
+		bytes.Repeat([]byte{0x22}, 600),
+		append(bytes.Repeat([]byte{0x33}, 254), 0x00),
+		[]byte(strings.Repeat("\x7E\xC0\x00", 100)),
+	}
+}
+
+// testRoundTrip encodes all frames through a List smaller than most encoded
+// frames, decoding each time the List is full, so that encoding and decoding
+// are both repeatedly interrupted and resumed.
+//
+// If empty is false, the codec cannot distinguish an empty frame from repeated
+// frame delimiters, so empty frames are not expected to be decoded.
+func testRoundTrip(t *testing.T, enc encoder, dec decoder, empty bool) {
+	t.Helper()
+	var l seq.List
+	l.Configure(make([]byte, 7))
+	p := make([]byte, 1024)
+	var got [][]byte
+	drain := func() {
+		for {
+			n, err := dec.Decode(&l, p)
+			if err == &nogc.ErrEmpty {
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			got = append(got, append([]byte{}, p[:n]...))
+		}
+	}
+	for _, f := range frames() {
+		for q := f; ; {
+			n, err := enc.Encode(&l, q)
+			if q = q[n:]; err == nil {
+				break
+			}
+			if err != &nogc.ErrWriteOverflow {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			drain()
+		}
+		for enc.End(&l) != nil {
+			drain()
+		}
+	}
+	drain()
+	var want [][]byte
+	for _, f := range frames() {
+		if empty || len(f) > 0 {
+			want = append(want, f)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Decode() frames = %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Errorf("Decode() frame %d = %x, want %x", i, got[i], want[i])
+		}
+	}
+}
+
+// testDecode decodes all frames from src into p, returning each frame or error
+// as a string.
+func testDecode(dec decoder, src string, p []byte) (got []string) {
+	var l seq.List
+	l.Configure([]byte(src))
+	l.Commit(len(src))
+	for {
+		n, err := dec.Decode(&l, p)
+		switch {
+		case err == &nogc.ErrEmpty:
+			return got
+		case err != nil:
+			got = append(got, err.Error())
+		default:
+			got = append(got, string(p[:n]))
+		}
+	}
+}
+
+func testAllocs(t *testing.T, enc encoder, dec decoder) {
+	t.Helper()
+	var r seq.Ring
+	r.Configure(make([]byte, 64))
+	q := []byte("\x00\x7E\xC0abc")
+	p := make([]byte, 16)
+	allocs := testing.AllocsPerRun(100, func() {
+		enc.Encode(&r, q)
+		enc.End(&r)
+		dec.Decode(&r, p)
+	})
+	if allocs != 0 {
+		t.Errorf("codec allocs = %v, want 0", allocs)
+	}
+}
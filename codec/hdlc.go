@@ -0,0 +1,153 @@
+package codec
+
+import (
+	"io"
+
+	"github.com/ardnew/nogc"
+)
+
+// Special bytes used by HDLC-like asynchronous framing (RFC 1662).
+const (
+	hdlcFlag = 0x7E // frame delimiter
+	hdlcEsc  = 0x7D // control escape
+	hdlcXor  = 0x20 // escaped bytes are XOR'd with this value
+)
+
+// HDLCEncoder encodes frames using the byte stuffing of HDLC-like asynchronous
+// framing defined by RFC 1662, as used by PPP.
+//
+// Each frame begins and ends with a flag byte. The flag and escape bytes in a
+// frame are escaped, and no other bytes are escaped. No frame check sequence is
+// added; callers needing one append it to the frame before encoding.
+type HDLCEncoder struct {
+	out  output
+	open bool // frame in progress
+}
+
+// Encode encodes p as the next bytes of the current frame, writes the encoded
+// bytes to dst, and returns the number of bytes of p that were encoded. The
+// first call to Encode after End begins a new frame.
+//
+// If dst returns an error, such as ErrWriteOverflow from a full List, Encode
+// returns the number of bytes of p encoded so far and that error. Any encoded
+// bytes not yet written are retained and written first by the next call to
+// Encode or End, which continues the same frame with p[n:].
+func (e *HDLCEncoder) Encode(dst io.ByteWriter, p []byte) (n int, err error) {
+	if e == nil {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if dst == nil || p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	if err = e.out.flush(dst); err != nil {
+		return 0, err
+	}
+	if !e.open {
+		e.out.put(hdlcFlag)
+		e.open = true
+	}
+	for _, c := range p {
+		if c == hdlcFlag || c == hdlcEsc {
+			e.out.put(hdlcEsc)
+			c ^= hdlcXor
+		}
+		e.out.put(c)
+		n++
+		if err = e.out.flush(dst); err != nil {
+			return n, err
+		}
+	}
+	return n, e.out.flush(dst)
+}
+
+// End ends the current frame and writes any remaining encoded bytes to dst.
+//
+// If dst returns an error, End returns that error, and the remaining bytes are
+// written by the next call to Encode or End.
+func (e *HDLCEncoder) End(dst io.ByteWriter) (err error) {
+	if e == nil {
+		return &nogc.ErrInvalidReceiver
+	}
+	if dst == nil {
+		return &nogc.ErrInvalidArgument
+	}
+	if e.open {
+		e.out.put(hdlcFlag)
+		e.open = false
+	}
+	return e.out.flush(dst)
+}
+
+// Reset discards any frame in progress and any encoded bytes not yet written.
+func (e *HDLCEncoder) Reset() {
+	if e == nil {
+		return
+	}
+	*e = HDLCEncoder{}
+}
+
+// HDLCDecoder decodes frames encoded using the byte stuffing of HDLC-like
+// asynchronous framing defined by RFC 1662.
+type HDLCDecoder struct {
+	in  input
+	esc bool // previous byte was an escape
+}
+
+// Decode reads bytes from src until a complete frame has been decoded into p,
+// and returns the length of the frame. Empty frames are ignored.
+//
+// If src returns an error, such as ErrEmpty from an empty List, Decode returns
+// 0 and that error. The partial frame is retained in p, and the next call to
+// Decode, which must be given the same p, continues decoding the same frame.
+//
+// If a frame is aborted by an escape followed by a flag, Decode returns
+// ErrInvalidFrame, and if the frame is longer than p, Decode returns
+// io.ErrShortBuffer. In both cases, the rest of the frame is discarded, and the
+// next call to Decode resynchronizes by decoding the frame following the next
+// flag.
+func (d *HDLCDecoder) Decode(src io.ByteReader, p []byte) (n int, err error) {
+	if d == nil {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if src == nil || p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	for {
+		c, err := src.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if c == hdlcFlag {
+			in, esc := d.in, d.esc
+			d.Reset()
+			switch {
+			case in.sync || in.n == 0 && !esc:
+				continue
+			case esc:
+				return 0, &nogc.ErrInvalidFrame
+			}
+			return in.n, nil
+		}
+		if d.in.sync {
+			continue
+		}
+		if d.esc {
+			d.esc = false
+			c ^= hdlcXor
+		} else if c == hdlcEsc {
+			d.esc = true
+			continue
+		}
+		if err = d.in.decode(p, c); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Reset discards any frame in progress.
+func (d *HDLCDecoder) Reset() {
+	if d == nil {
+		return
+	}
+	*d = HDLCDecoder{}
+}
@@ -0,0 +1,69 @@
+package codec
+
+import (
+	"strings"
+	"testing"
+
+	seq "github.com/ardnew/nogc/fifo"
+)
+
+func TestHDLCEncoder_Encode(t *testing.T) {
+	tests := []struct {
+		name string
+		p    string
+		want string
+	}{
+		{name: "plain", p: "abc", want: "~abc~"},
+		{name: "escaped", p: "a~b}c", want: "~a}^b}]c~"},
+		{name: "empty", p: "", want: "~~"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l seq.List
+			l.Configure(make([]byte, 16))
+			var e HDLCEncoder
+			if n, err := e.Encode(&l, []byte(tt.p)); n != len(tt.p) || err != nil {
+				t.Fatalf("HDLCEncoder.Encode() = %v, %v, want %v, %v", n, err, len(tt.p), nil)
+			}
+			if err := e.End(&l); err != nil {
+				t.Fatalf("HDLCEncoder.End() error = %v", err)
+			}
+			got := make([]byte, 16)
+			n, _ := l.Read(got)
+			if string(got[:n]) != tt.want {
+				t.Errorf("HDLCEncoder.Encode() = %q, want %q", got[:n], tt.want)
+			}
+		})
+	}
+}
+
+func TestHDLCDecoder_Decode(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{name: "frames", src: "~ab~~~c}^}]~", want: []string{"ab", "c~}"}},
+		{name: "no-leading-flag", src: "ab~cd~", want: []string{"ab", "cd"}},
+		{name: "escaped-other", src: "~a}\x01~", want: []string{"a!"}},
+		{name: "abort", src: "a}~de~", want: []string{"invalid frame", "de"}},
+		{name: "too-long", src: "abcde~fg~", want: []string{"short buffer", "fg"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d HDLCDecoder
+			got := testDecode(&d, tt.src, make([]byte, 4))
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("HDLCDecoder.Decode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHDLC_RoundTrip(t *testing.T) {
+	testRoundTrip(t, &HDLCEncoder{}, &HDLCDecoder{}, false)
+}
+
+func TestHDLC_Allocs(t *testing.T) {
+	testAllocs(t, &HDLCEncoder{}, &HDLCDecoder{})
+}
@@ -0,0 +1,165 @@
+package codec
+
+import (
+	"io"
+
+	"github.com/ardnew/nogc"
+)
+
+// Special bytes used by SLIP (RFC 1055).
+const (
+	slipEnd    = 0xC0 // frame delimiter
+	slipEsc    = 0xDB // escape
+	slipEscEnd = 0xDC // escaped frame delimiter
+	slipEscEsc = 0xDD // escaped escape
+)
+
+// SLIPEncoder encodes frames using the Serial Line Internet Protocol (SLIP)
+// framing defined by RFC 1055.
+//
+// Each frame begins and ends with a delimiter, so that a receiver discards any
+// noise received between frames.
+type SLIPEncoder struct {
+	out  output
+	open bool // frame in progress
+}
+
+// Encode encodes p as the next bytes of the current frame, writes the encoded
+// bytes to dst, and returns the number of bytes of p that were encoded. The
+// first call to Encode after End begins a new frame.
+//
+// If dst returns an error, such as ErrWriteOverflow from a full List, Encode
+// returns the number of bytes of p encoded so far and that error. Any encoded
+// bytes not yet written are retained and written first by the next call to
+// Encode or End, which continues the same frame with p[n:].
+func (e *SLIPEncoder) Encode(dst io.ByteWriter, p []byte) (n int, err error) {
+	if e == nil {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if dst == nil || p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	if err = e.out.flush(dst); err != nil {
+		return 0, err
+	}
+	if !e.open {
+		e.out.put(slipEnd)
+		e.open = true
+	}
+	for _, c := range p {
+		switch c {
+		case slipEnd:
+			e.out.put(slipEsc)
+			e.out.put(slipEscEnd)
+		case slipEsc:
+			e.out.put(slipEsc)
+			e.out.put(slipEscEsc)
+		default:
+			e.out.put(c)
+		}
+		n++
+		if err = e.out.flush(dst); err != nil {
+			return n, err
+		}
+	}
+	return n, e.out.flush(dst)
+}
+
+// End ends the current frame and writes any remaining encoded bytes to dst.
+//
+// If dst returns an error, End returns that error, and the remaining bytes are
+// written by the next call to Encode or End.
+func (e *SLIPEncoder) End(dst io.ByteWriter) (err error) {
+	if e == nil {
+		return &nogc.ErrInvalidReceiver
+	}
+	if dst == nil {
+		return &nogc.ErrInvalidArgument
+	}
+	if e.open {
+		e.out.put(slipEnd)
+		e.open = false
+	}
+	return e.out.flush(dst)
+}
+
+// Reset discards any frame in progress and any encoded bytes not yet written.
+func (e *SLIPEncoder) Reset() {
+	if e == nil {
+		return
+	}
+	*e = SLIPEncoder{}
+}
+
+// SLIPDecoder decodes frames encoded using the Serial Line Internet Protocol
+// (SLIP) framing defined by RFC 1055.
+type SLIPDecoder struct {
+	in  input
+	esc bool // previous byte was an escape
+}
+
+// Decode reads bytes from src until a complete frame has been decoded into p,
+// and returns the length of the frame. Empty frames are ignored.
+//
+// If src returns an error, such as ErrEmpty from an empty List, Decode returns
+// 0 and that error. The partial frame is retained in p, and the next call to
+// Decode, which must be given the same p, continues decoding the same frame.
+//
+// If an invalid escape sequence is received, Decode returns ErrInvalidFrame,
+// and if the frame is longer than p, Decode returns io.ErrShortBuffer. In both
+// cases, the rest of the frame is discarded, and the next call to Decode
+// resynchronizes by decoding the frame following the next delimiter.
+func (d *SLIPDecoder) Decode(src io.ByteReader, p []byte) (n int, err error) {
+	if d == nil {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if src == nil || p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	for {
+		c, err := src.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if c == slipEnd {
+			in, esc := d.in, d.esc
+			d.Reset()
+			switch {
+			case in.sync || in.n == 0 && !esc:
+				continue
+			case esc:
+				return 0, &nogc.ErrInvalidFrame
+			}
+			return in.n, nil
+		}
+		if d.in.sync {
+			continue
+		}
+		if d.esc {
+			d.esc = false
+			switch c {
+			case slipEscEnd:
+				c = slipEnd
+			case slipEscEsc:
+				c = slipEsc
+			default:
+				d.in.sync = true
+				return 0, &nogc.ErrInvalidFrame
+			}
+		} else if c == slipEsc {
+			d.esc = true
+			continue
+		}
+		if err = d.in.decode(p, c); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Reset discards any frame in progress.
+func (d *SLIPDecoder) Reset() {
+	if d == nil {
+		return
+	}
+	*d = SLIPDecoder{}
+}
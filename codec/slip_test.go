@@ -0,0 +1,69 @@
+package codec
+
+import (
+	"strings"
+	"testing"
+
+	seq "github.com/ardnew/nogc/fifo"
+)
+
+func TestSLIPEncoder_Encode(t *testing.T) {
+	tests := []struct {
+		name string
+		p    string
+		want string
+	}{
+		{name: "plain", p: "abc", want: "\xC0abc\xC0"},
+		{name: "escaped", p: "a\xC0b\xDBc", want: "\xC0a\xDB\xDCb\xDB\xDDc\xC0"},
+		{name: "empty", p: "", want: "\xC0\xC0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l seq.List
+			l.Configure(make([]byte, 16))
+			var e SLIPEncoder
+			if n, err := e.Encode(&l, []byte(tt.p)); n != len(tt.p) || err != nil {
+				t.Fatalf("SLIPEncoder.Encode() = %v, %v, want %v, %v", n, err, len(tt.p), nil)
+			}
+			if err := e.End(&l); err != nil {
+				t.Fatalf("SLIPEncoder.End() error = %v", err)
+			}
+			got := make([]byte, 16)
+			n, _ := l.Read(got)
+			if string(got[:n]) != tt.want {
+				t.Errorf("SLIPEncoder.Encode() = %q, want %q", got[:n], tt.want)
+			}
+		})
+	}
+}
+
+func TestSLIPDecoder_Decode(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{name: "frames", src: "\xC0ab\xC0\xC0\xC0c\xDB\xDC\xDB\xDD\xC0", want: []string{"ab", "c\xC0\xDB"}},
+		{name: "no-leading-end", src: "ab\xC0cd\xC0", want: []string{"ab", "cd"}},
+		{name: "bad-escape", src: "a\xDBxbc\xC0de\xC0", want: []string{"invalid frame", "de"}},
+		{name: "escaped-end", src: "a\xDB\xC0de\xC0", want: []string{"invalid frame", "de"}},
+		{name: "too-long", src: "abcde\xC0fg\xC0", want: []string{"short buffer", "fg"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d SLIPDecoder
+			got := testDecode(&d, tt.src, make([]byte, 4))
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("SLIPDecoder.Decode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSLIP_RoundTrip(t *testing.T) {
+	testRoundTrip(t, &SLIPEncoder{}, &SLIPDecoder{}, false)
+}
+
+func TestSLIP_Allocs(t *testing.T) {
+	testAllocs(t, &SLIPEncoder{}, &SLIPDecoder{})
+}
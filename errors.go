@@ -8,6 +8,10 @@ package nogc
 // OutOfRange
 // WriteOverflow
 // ReadOverflow
+// Empty
+// Closed
+// InvalidFrame
+// InvalidUnread
 
 type (
 	InvalidReceiver struct{}
@@ -15,6 +19,10 @@ type (
 	OutOfRange      struct{}
 	WriteOverflow   struct{}
 	ReadOverflow    struct{}
+	Empty           struct{}
+	Closed          struct{}
+	InvalidFrame    struct{}
+	InvalidUnread   struct{}
 )
 
 var (
@@ -23,6 +31,10 @@ var (
 	ErrOutOfRange      OutOfRange
 	ErrWriteOverflow   WriteOverflow
 	ErrReadOverflow    ReadOverflow
+	ErrEmpty           Empty
+	ErrClosed          Closed
+	ErrInvalidFrame    InvalidFrame
+	ErrInvalidUnread   InvalidUnread
 )
 
 func (e *InvalidReceiver) Error() string {
@@ -44,3 +56,19 @@ func (e *WriteOverflow) Error() string {
 func (e *ReadOverflow) Error() string {
 	return "read overflow"
 }
+
+func (e *Empty) Error() string {
+	return "empty"
+}
+
+func (e *Closed) Error() string {
+	return "closed"
+}
+
+func (e *InvalidFrame) Error() string {
+	return "invalid frame"
+}
+
+func (e *InvalidUnread) Error() string {
+	return "invalid unread"
+}
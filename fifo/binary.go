@@ -0,0 +1,118 @@
+package seq
+
+import (
+	"encoding/binary"
+
+	"github.com/ardnew/nogc"
+)
+
+// Binary encoding of a buf, in order:
+//
+//	version  1 byte
+//	flags    1 byte, bitwise OR of binaryDequeue, binaryShut, and binaryEOF
+//	capacity 4 bytes, little-endian
+//	length   4 bytes, little-endian
+//	contents length bytes, from first-in to last-in
+const (
+	binaryVersion    = 1
+	binaryHeaderSize = 10
+)
+
+// Bits in the flags of a binary encoding.
+const (
+	binaryDequeue = 1 << iota // mode is dequeue (Ring)
+	binaryShut                // closed for writing
+	binaryEOF                 // io.EOF is returned whenever empty
+)
+
+// BinaryLen returns the length of the binary encoding of b returned by
+// MarshalBinary and appended by AppendBinary.
+func (b *buf) BinaryLen() int {
+	if b == nil || !b.valid {
+		return 0
+	}
+	return binaryHeaderSize + b.Len()
+}
+
+// AppendBinary appends the binary encoding of the configuration and contents of
+// b to p and returns the extended slice. No bytes are dequeued.
+//
+// Like append, AppendBinary only allocates if there are fewer than BinaryLen
+// bytes of spare capacity in p, so callers may provide storage by passing an
+// empty slice with sufficient capacity.
+func (b *buf) AppendBinary(p []byte) ([]byte, error) {
+	if b == nil || !b.valid {
+		return p, &nogc.ErrInvalidReceiver
+	}
+	var flags byte
+	if b.mode == dequeue {
+		flags |= binaryDequeue
+	}
+	if b.shut {
+		flags |= binaryShut
+	}
+	if b.eof {
+		flags |= binaryEOF
+	}
+	n := b.distance(b.head, b.tail)
+	var h [binaryHeaderSize]byte
+	h[0] = binaryVersion
+	h[1] = flags
+	binary.LittleEndian.PutUint32(h[2:], b.capt)
+	binary.LittleEndian.PutUint32(h[6:], n)
+	r1, r2 := b.peekSpans(0, n)
+	p = append(p, h[:]...)
+	p = append(p, r1...)
+	return append(p, r2...), nil
+}
+
+// MarshalBinary returns the binary encoding of the configuration and contents
+// of b in a newly allocated slice. No bytes are dequeued.
+//
+// Use AppendBinary to encode into storage provided by the caller instead.
+func (b *buf) MarshalBinary() (data []byte, err error) {
+	if b == nil || !b.valid {
+		return nil, &nogc.ErrInvalidReceiver
+	}
+	return b.AppendBinary(make([]byte, 0, b.BinaryLen()))
+}
+
+// UnmarshalBinary restores the contents of b, and whether it is closed, from
+// the binary encoding in data returned by MarshalBinary or AppendBinary.
+//
+// The storage of b is not changed, so b must already be initialized by calling
+// Configure. Bytes already in b are discarded.
+//
+// If data is not a valid encoding, or if it is the encoding of a List but b is
+// a Ring or vice versa, returns ErrInvalidArgument. If the contents in data are
+// longer than the capacity of b, returns ErrOutOfRange. In both cases, b is not
+// modified. The capacity of b need not equal the capacity of the encoded buf.
+func (b *buf) UnmarshalBinary(data []byte) (err error) {
+	if b == nil || !b.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	if len(data) < binaryHeaderSize || data[0] != binaryVersion {
+		return &nogc.ErrInvalidArgument
+	}
+	flags := data[1]
+	capt := binary.LittleEndian.Uint32(data[2:])
+	n := binary.LittleEndian.Uint32(data[6:])
+	data = data[binaryHeaderSize:]
+	if flags&^(binaryDequeue|binaryShut|binaryEOF) != 0 ||
+		mode(flags&binaryDequeue != 0) != b.mode ||
+		n > capt || uint64(n) != uint64(len(data)) {
+		return &nogc.ErrInvalidArgument
+	}
+	if n > b.capt {
+		return &nogc.ErrOutOfRange
+	}
+	copy(b.Byte, data)
+	b.head = 0
+	b.tail = n
+	b.shut = flags&binaryShut != 0
+	b.eof = flags&binaryEOF != 0
+	b.unread = 0
+	b.rsize = 0
+	b.mark()
+	return nil
+}
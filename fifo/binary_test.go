@@ -0,0 +1,100 @@
+package seq
+
+import (
+	"encoding"
+	"testing"
+
+	"github.com/ardnew/nogc"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*List)(nil)
+	_ encoding.BinaryUnmarshaler = (*List)(nil)
+	_ encoding.BinaryMarshaler   = (*Ring)(nil)
+	_ encoding.BinaryUnmarshaler = (*Ring)(nil)
+)
+
+func Test_buf_MarshalBinary(t *testing.T) {
+	b := &buf{Byte: []byte("cdeab"), capt: 5, head: 3, tail: 7, mode: dequeue, shut: true, valid: true}
+	data, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatalf("buf.MarshalBinary() error = %v", err)
+	}
+	want := "\x01\x03\x05\x00\x00\x00\x04\x00\x00\x00abcd"
+	if string(data) != want {
+		t.Errorf("buf.MarshalBinary() = %q, want %q", data, want)
+	}
+	if got := queued(b); got != "abcd" {
+		t.Errorf("buf.MarshalBinary() queued = %q, want %q", got, "abcd")
+	}
+}
+
+func Test_buf_UnmarshalBinary(t *testing.T) {
+	src := &buf{Byte: []byte("cdeab"), capt: 5, head: 3, tail: 7, valid: true}
+	data, _ := src.MarshalBinary()
+	tests := []struct {
+		name    string
+		capt    int
+		mode    mode
+		data    []byte
+		wantB   string
+		wantErr error
+	}{
+		{name: "same", capt: 5, data: data, wantB: "abcd"},
+		{name: "larger", capt: 9, data: data, wantB: "abcd"},
+		{name: "exact", capt: 4, data: data, wantB: "abcd"},
+		{name: "smaller", capt: 3, data: data, wantB: "xyz", wantErr: &nogc.ErrOutOfRange},
+		{name: "mode", capt: 5, mode: dequeue, data: data, wantB: "xyz", wantErr: &nogc.ErrInvalidArgument},
+		{name: "short", capt: 5, data: data[:len(data)-1], wantB: "xyz", wantErr: &nogc.ErrInvalidArgument},
+		{name: "version", capt: 5, data: append([]byte{0}, data[1:]...), wantB: "xyz", wantErr: &nogc.ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &buf{}
+			b.valid = b.init(make([]byte, tt.capt), uint32(tt.capt), tt.mode)
+			b.Write([]byte("xyz"))
+			if err := b.UnmarshalBinary(tt.data); err != tt.wantErr {
+				t.Errorf("buf.UnmarshalBinary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := queued(b); got != tt.wantB {
+				t.Errorf("buf.UnmarshalBinary() queued = %q, want %q", got, tt.wantB)
+			}
+		})
+	}
+}
+
+func TestList_UnmarshalBinary(t *testing.T) {
+	var l List
+	l.Configure(make([]byte, 4))
+	l.Write([]byte("abcd"))
+	l.Read(make([]byte, 2))
+	l.Write([]byte("ef"))
+	l.CloseWrite()
+	data, _ := l.MarshalBinary()
+	var r List
+	if err := r.UnmarshalBinary(data); err != &nogc.ErrInvalidReceiver {
+		t.Errorf("List.UnmarshalBinary() error = %v, want %v", err, &nogc.ErrInvalidReceiver)
+	}
+	r.Configure(make([]byte, 6))
+	if err := r.UnmarshalBinary(data); err != nil {
+		t.Fatalf("List.UnmarshalBinary() error = %v", err)
+	}
+	if _, err := r.Write([]byte("g")); err != &nogc.ErrClosed {
+		t.Errorf("List.Write() error = %v, want %v", err, &nogc.ErrClosed)
+	}
+	if got := queued(&r.buf); got != "cdef" {
+		t.Errorf("List.UnmarshalBinary() queued = %q, want %q", got, "cdef")
+	}
+}
+
+func Test_buf_AppendBinary_Allocs(t *testing.T) {
+	b := &buf{Byte: []byte("cdeab"), capt: 5, head: 3, tail: 7, valid: true}
+	p := make([]byte, 0, b.BinaryLen())
+	allocs := testing.AllocsPerRun(100, func() {
+		data, _ := b.AppendBinary(p[:0])
+		b.UnmarshalBinary(data)
+	})
+	if allocs != 0 {
+		t.Errorf("buf.AppendBinary() allocs = %v, want 0", allocs)
+	}
+}
@@ -0,0 +1,174 @@
+package seq
+
+import (
+	"github.com/ardnew/nogc"
+)
+
+// BipBuffer defines a fixed-length queue of bytes, like List, that always
+// provides contiguous regions of the backing array for both writing and reading.
+//
+// A bipartite buffer maintains up to two regions of committed bytes: region A,
+// which holds the oldest bytes, and region B, which begins at the start of the
+// backing array once there is not enough room after region A. Bytes are always
+// read from region A, and region B becomes region A once region A is empty:
+//
+//	(0123456789A) === Array index reference
+//	[...AAAA....]     Region A [3..6] only, free-space [7..A] and [0..2]
+//	[BB.AAAA....]     Region A [3..6] and region B [0..1], free-space [2..2]
+//	[BB.........]     Region A is empty, region B becomes region A [0..1]
+//
+// Unlike List, a write of n bytes never straddles the end of the backing array,
+// at the cost of sometimes leaving free space unused at the end of it.
+type BipBuffer struct {
+	Byte  []byte
+	capt  uint32
+	aLo   uint32 // start of region A
+	aHi   uint32 // end of region A
+	bHi   uint32 // end of region B, which always starts at index 0
+	rLo   uint32 // start of reserved region
+	rHi   uint32 // end of reserved region
+	valid bool
+}
+
+// Configure initializes q using all of p as storage.
+// The initial length of q is 0; any data already in p may be overwritten.
+// The capacity of q is permanently len(p).
+// Callers must not modify p after initializing.
+func (q *BipBuffer) Configure(p []byte) (ok bool) {
+	if q == nil {
+		return false
+	}
+	q.Byte = p
+	q.capt = uint32(len(p))
+	q.valid = len(p) > 0
+	q.Reset()
+	return q.valid
+}
+
+// Len returns the number of committed bytes.
+func (q *BipBuffer) Len() int {
+	if q == nil || !q.valid {
+		return 0
+	}
+	return int(q.aHi - q.aLo + q.bHi)
+}
+
+// Cap returns the byte capacity.
+func (q *BipBuffer) Cap() int {
+	if q == nil || !q.valid {
+		return 0
+	}
+	return int(q.capt)
+}
+
+// Reset sets the number of bytes to 0 and cancels any reservation.
+func (q *BipBuffer) Reset() {
+	if q == nil || !q.valid {
+		return
+	}
+	q.aLo, q.aHi, q.bHi = 0, 0, 0
+	q.rLo, q.rHi = 0, 0
+}
+
+// Reserve returns a view of n contiguous bytes of free space in q for the
+// caller to write into, to be enqueued by a subsequent call to Commit.
+// Any previous reservation that was not committed is cancelled.
+//
+// Reserve never returns fewer than n bytes. If there is no contiguous region of
+// at least n bytes of free space, returns ErrWriteOverflow.
+func (q *BipBuffer) Reserve(n int) (p []byte, err error) {
+	if q == nil || !q.valid {
+		return nil, &nogc.ErrInvalidReceiver
+	}
+	if n < 0 {
+		return nil, &nogc.ErrInvalidArgument
+	}
+	if q.aLo == q.aHi && q.bHi == 0 {
+		// Region A is empty, and so is region B. Move region A to the start of the
+		// backing array so that all of it is available.
+		q.aLo, q.aHi = 0, 0
+	}
+	nr := uint32(n)
+	switch {
+	case q.bHi > 0:
+		// Region B exists, so new bytes must follow it, up to start of region A.
+		if q.aLo-q.bHi < nr {
+			return nil, &nogc.ErrWriteOverflow
+		}
+		q.rLo = q.bHi
+	case q.capt-q.aHi >= nr:
+		// Enough room follows region A.
+		q.rLo = q.aHi
+	case q.aLo >= nr:
+		// Enough room precedes region A, which will start region B.
+		q.rLo = 0
+	default:
+		return nil, &nogc.ErrWriteOverflow
+	}
+	q.rHi = q.rLo + nr
+	return q.Byte[q.rLo:q.rHi:q.rHi], nil
+}
+
+// Commit enqueues the first n bytes of the view returned by the most recent call
+// to Reserve and cancels the reservation.
+//
+// If n is negative or greater than the reserved length, no bytes are enqueued
+// and Commit returns ErrOutOfRange.
+func (q *BipBuffer) Commit(n int) (err error) {
+	if q == nil || !q.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	if n < 0 || uint32(n) > q.rHi-q.rLo {
+		return &nogc.ErrOutOfRange
+	}
+	nc := uint32(n)
+	switch {
+	case nc == 0:
+	case q.rLo == q.aHi && q.bHi == 0:
+		q.aHi += nc
+	default:
+		// Reservation is at the start of the backing array, either following or
+		// beginning region B.
+		q.bHi += nc
+		if q.aLo == q.aHi {
+			// Region A was consumed since the reservation was made; region B
+			// becomes the new region A.
+			q.aLo, q.aHi, q.bHi = 0, q.bHi, 0
+		}
+	}
+	q.rLo, q.rHi = 0, 0
+	return nil
+}
+
+// Block returns a view of the oldest contiguous block of committed bytes in q,
+// which are all of the bytes in q if they have not wrapped around the end of the
+// backing array.
+//
+// No bytes are dequeued. Callers read directly from the view and then call
+// Consume to dequeue the bytes that were used. The view is only valid until the
+// next call to a method that modifies q.
+func (q *BipBuffer) Block() []byte {
+	if q == nil || !q.valid || q.aLo == q.aHi {
+		return nil
+	}
+	return q.Byte[q.aLo:q.aHi:q.aHi]
+}
+
+// Consume dequeues the first n bytes of the view returned by Block.
+//
+// If n is negative or greater than the length of that view, no bytes are
+// dequeued and Consume returns ErrOutOfRange.
+func (q *BipBuffer) Consume(n int) (err error) {
+	if q == nil || !q.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	if n < 0 || uint32(n) > q.aHi-q.aLo {
+		return &nogc.ErrOutOfRange
+	}
+	q.aLo += uint32(n)
+	if q.aLo == q.aHi && q.bHi > 0 {
+		// Region A is exhausted; region B becomes the new region A.
+		q.aLo, q.aHi, q.bHi = 0, q.bHi, 0
+	}
+	return nil
+}
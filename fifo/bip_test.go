@@ -0,0 +1,155 @@
+package seq
+
+import (
+	"testing"
+
+	"github.com/ardnew/nogc"
+)
+
+func TestBipBuffer_Reserve(t *testing.T) {
+	type fields struct {
+		aLo uint32
+		aHi uint32
+		bHi uint32
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		n       int
+		wantLo  uint32
+		wantErr bool
+	}{
+		{name: "empty", fields: fields{}, n: 10, wantLo: 0},
+		{name: "empty-offset", fields: fields{aLo: 7, aHi: 7}, n: 10, wantLo: 0},
+		{name: "after-a", fields: fields{aLo: 2, aHi: 5}, n: 5, wantLo: 5},
+		{name: "before-a", fields: fields{aLo: 4, aHi: 8}, n: 3, wantLo: 0},
+		{name: "neither", fields: fields{aLo: 3, aHi: 7}, n: 4, wantErr: true},
+		{name: "after-b", fields: fields{aLo: 6, aHi: 9, bHi: 2}, n: 4, wantLo: 2},
+		{name: "after-b-full", fields: fields{aLo: 6, aHi: 9, bHi: 2}, n: 5, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &BipBuffer{
+				Byte:  make([]byte, 10),
+				capt:  10,
+				aLo:   tt.fields.aLo,
+				aHi:   tt.fields.aHi,
+				bHi:   tt.fields.bHi,
+				valid: true,
+			}
+			got, err := q.Reserve(tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BipBuffer.Reserve() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != tt.n || q.rLo != tt.wantLo {
+				t.Errorf("BipBuffer.Reserve() = [%d:%d], want [%d:%d]", q.rLo, q.rLo+uint32(len(got)), tt.wantLo, tt.wantLo+uint32(tt.n))
+			}
+		})
+	}
+}
+
+func TestBipBuffer_Commit(t *testing.T) {
+	var q BipBuffer
+	q.Configure(make([]byte, 8))
+	write := func(s string) bool {
+		p, err := q.Reserve(len(s))
+		if err != nil {
+			return false
+		}
+		copy(p, s)
+		return q.Commit(len(s)) == nil
+	}
+	read := func() string {
+		p := q.Block()
+		q.Consume(len(p))
+		return string(p)
+	}
+	if !write("abcde") || !write("fg") {
+		t.Fatalf("BipBuffer.Reserve() failed with free space")
+	}
+	if write("hi") {
+		t.Fatalf("BipBuffer.Reserve() succeeded without contiguous space")
+	}
+	if err := q.Consume(3); err != nil {
+		t.Fatalf("BipBuffer.Consume() error = %v", err)
+	}
+	// Region A is [3..6], region B starts at 0.
+	if !write("hij") {
+		t.Fatalf("BipBuffer.Reserve() failed with free space before region A")
+	}
+	if q.Len() != 7 {
+		t.Errorf("BipBuffer.Len() = %v, want %v", q.Len(), 7)
+	}
+	for _, want := range []string{"defg", "hij", ""} {
+		if got := read(); got != want {
+			t.Errorf("BipBuffer.Block() = %q, want %q", got, want)
+		}
+	}
+	p, _ := q.Reserve(8)
+	if len(p) != 8 {
+		t.Errorf("BipBuffer.Reserve() = %d bytes, want %d", len(p), 8)
+	}
+	if err := q.Commit(9); err == nil {
+		t.Errorf("BipBuffer.Commit() error = %v, wantErr %v", err, true)
+	}
+}
+
+func TestBipBuffer_Consume(t *testing.T) {
+	var q BipBuffer
+	q.Configure(make([]byte, 8))
+	p, _ := q.Reserve(4)
+	copy(p, "abcd")
+	q.Commit(4)
+	// Reserve after region A, then drain region A before committing.
+	p, _ = q.Reserve(2)
+	copy(p, "ef")
+	if err := q.Consume(4); err != nil {
+		t.Fatalf("BipBuffer.Consume() error = %v", err)
+	}
+	if err := q.Consume(1); err == nil {
+		t.Errorf("BipBuffer.Consume() error = %v, wantErr %v", err, true)
+	}
+	q.Commit(2)
+	if got := string(q.Block()); got != "ef" {
+		t.Errorf("BipBuffer.Block() = %q, want %q", got, "ef")
+	}
+}
+
+func TestBipBuffer_Consume_Wrapped(t *testing.T) {
+	var q BipBuffer
+	q.Configure(make([]byte, 8))
+	p, _ := q.Reserve(6)
+	copy(p, "abcdef")
+	q.Commit(6)
+	q.Consume(4)
+	// Reserve before region A, then drain region A before committing, so that
+	// the committed bytes begin region B while region A is empty.
+	p, err := q.Reserve(3)
+	if err != nil {
+		t.Fatalf("BipBuffer.Reserve() error = %v", err)
+	}
+	copy(p, "ghi")
+	q.Consume(2)
+	if err := q.Commit(3); err != nil {
+		t.Fatalf("BipBuffer.Commit() error = %v", err)
+	}
+	if got := string(q.Block()); got != "ghi" || q.Len() != 3 {
+		t.Errorf("BipBuffer.Block() = %q, Len %d, want %q, %d", got, q.Len(), "ghi", 3)
+	}
+	if _, err := q.Reserve(7); err != &nogc.ErrWriteOverflow {
+		t.Errorf("BipBuffer.Reserve() error = %v, want %v", err, &nogc.ErrWriteOverflow)
+	}
+	p, err = q.Reserve(5)
+	if err != nil {
+		t.Fatalf("BipBuffer.Reserve() error = %v", err)
+	}
+	copy(p, "jklmn")
+	q.Commit(5)
+	if got := string(q.Block()); got != "ghijklmn" {
+		t.Errorf("BipBuffer.Block() = %q, want %q", got, "ghijklmn")
+	}
+}
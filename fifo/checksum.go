@@ -0,0 +1,186 @@
+package seq
+
+import (
+	"hash/crc32"
+
+	"github.com/ardnew/nogc"
+)
+
+// Parameters of the CRC-8 (SMBus) and CRC-16/CCITT-FALSE algorithms.
+const (
+	crc8Poly  = 0x07
+	crc16Poly = 0x1021
+	crc16Init = 0xFFFF
+)
+
+// Moduli of the Fletcher-16 and Adler-32 algorithms.
+const (
+	fletcher16Mod = 255
+	adler32Mod    = 65521
+	adler32NMax   = 5552 // max bytes summed before reducing without overflow
+)
+
+// Tables of precomputed CRC remainders, indexed by byte.
+var (
+	crc8Table  = makeCRC8Table()
+	crc16Table = makeCRC16Table()
+)
+
+// makeCRC8Table returns the table of CRC-8 remainders.
+func makeCRC8Table() (t [256]uint8) {
+	for i := range t {
+		c := uint8(i)
+		for k := 0; k < 8; k++ {
+			if c&0x80 != 0 {
+				c = c<<1 ^ crc8Poly
+			} else {
+				c <<= 1
+			}
+		}
+		t[i] = c
+	}
+	return
+}
+
+// makeCRC16Table returns the table of CRC-16/CCITT remainders.
+func makeCRC16Table() (t [256]uint16) {
+	for i := range t {
+		c := uint16(i) << 8
+		for k := 0; k < 8; k++ {
+			if c&0x8000 != 0 {
+				c = c<<1 ^ crc16Poly
+			} else {
+				c <<= 1
+			}
+		}
+		t[i] = c
+	}
+	return
+}
+
+// checkSpans returns the one or two contiguous regions of the backing array
+// that together form the n unread bytes starting at offset off from the next
+// unread byte. If the range is not within the unread bytes of b, returns
+// ErrOutOfRange.
+func (b *buf) checkSpans(off, n int) (r1, r2 []byte, err error) {
+	if b == nil || !b.valid {
+		return nil, nil, &nogc.ErrInvalidReceiver
+	}
+	if off < 0 || n < 0 || off > b.Len()-n {
+		return nil, nil, &nogc.ErrOutOfRange
+	}
+	r1, r2 = b.peekSpans(uint32(off), uint32(n))
+	return r1, r2, nil
+}
+
+// CRC8 returns the CRC-8 checksum, with polynomial 0x07 and initial value 0
+// (SMBus), of the n unread bytes starting at offset off from the next unread
+// byte in b. No bytes are dequeued.
+//
+// If the range is not within the unread bytes of b, returns ErrOutOfRange.
+func (b *buf) CRC8(off, n int) (sum uint8, err error) {
+	r1, r2, err := b.checkSpans(off, n)
+	if err != nil {
+		return 0, err
+	}
+	return crc8Update(crc8Update(0, r1), r2), nil
+}
+
+// CRC16 returns the CRC-16/CCITT checksum, with polynomial 0x1021 and initial
+// value 0xFFFF (CCITT-FALSE), of the n unread bytes starting at offset off from
+// the next unread byte in b. No bytes are dequeued.
+//
+// If the range is not within the unread bytes of b, returns ErrOutOfRange.
+func (b *buf) CRC16(off, n int) (sum uint16, err error) {
+	r1, r2, err := b.checkSpans(off, n)
+	if err != nil {
+		return 0, err
+	}
+	return crc16Update(crc16Update(crc16Init, r1), r2), nil
+}
+
+// CRC32 returns the CRC-32 checksum, using the IEEE polynomial as in
+// crc32.ChecksumIEEE, of the n unread bytes starting at offset off from the next
+// unread byte in b. No bytes are dequeued.
+//
+// If the range is not within the unread bytes of b, returns ErrOutOfRange.
+func (b *buf) CRC32(off, n int) (sum uint32, err error) {
+	r1, r2, err := b.checkSpans(off, n)
+	if err != nil {
+		return 0, err
+	}
+	return crc32.Update(crc32.Update(0, crc32.IEEETable, r1), crc32.IEEETable, r2), nil
+}
+
+// Fletcher16 returns the Fletcher-16 checksum of the n unread bytes starting at
+// offset off from the next unread byte in b. No bytes are dequeued.
+//
+// If the range is not within the unread bytes of b, returns ErrOutOfRange.
+func (b *buf) Fletcher16(off, n int) (sum uint16, err error) {
+	r1, r2, err := b.checkSpans(off, n)
+	if err != nil {
+		return 0, err
+	}
+	s1, s2 := fletcher16Update(0, 0, r1)
+	s1, s2 = fletcher16Update(s1, s2, r2)
+	return uint16(s2<<8 | s1), nil
+}
+
+// Adler32 returns the Adler-32 checksum, as in adler32.Checksum, of the n unread
+// bytes starting at offset off from the next unread byte in b. No bytes are
+// dequeued.
+//
+// If the range is not within the unread bytes of b, returns ErrOutOfRange.
+func (b *buf) Adler32(off, n int) (sum uint32, err error) {
+	r1, r2, err := b.checkSpans(off, n)
+	if err != nil {
+		return 0, err
+	}
+	return adler32Update(adler32Update(1, r1), r2), nil
+}
+
+// crc8Update returns the result of adding the bytes in p to crc.
+func crc8Update(crc uint8, p []byte) uint8 {
+	for _, c := range p {
+		crc = crc8Table[crc^c]
+	}
+	return crc
+}
+
+// crc16Update returns the result of adding the bytes in p to crc.
+func crc16Update(crc uint16, p []byte) uint16 {
+	for _, c := range p {
+		crc = crc<<8 ^ crc16Table[byte(crc>>8)^c]
+	}
+	return crc
+}
+
+// fletcher16Update returns the result of adding the bytes in p to the running
+// sums s1 and s2.
+func fletcher16Update(s1, s2 uint32, p []byte) (uint32, uint32) {
+	for _, c := range p {
+		s1 = (s1 + uint32(c)) % fletcher16Mod
+		s2 = (s2 + s1) % fletcher16Mod
+	}
+	return s1, s2
+}
+
+// adler32Update returns the result of adding the bytes in p to the checksum d.
+func adler32Update(d uint32, p []byte) uint32 {
+	s1, s2 := d&0xFFFF, d>>16
+	for len(p) > 0 {
+		q := p
+		if len(q) > adler32NMax {
+			q, p = q[:adler32NMax], q[adler32NMax:]
+		} else {
+			p = nil
+		}
+		for _, c := range q {
+			s1 += uint32(c)
+			s2 += s1
+		}
+		s1 %= adler32Mod
+		s2 %= adler32Mod
+	}
+	return s2<<16 | s1
+}
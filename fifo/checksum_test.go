@@ -0,0 +1,99 @@
+package seq
+
+import (
+	"bytes"
+	"hash/adler32"
+	"hash/crc32"
+	"testing"
+
+	"github.com/ardnew/nogc"
+)
+
+func Test_buf_CRC(t *testing.T) {
+	// Queued bytes are "123456789", wrapped after the fourth byte.
+	b := &buf{Byte: []byte("56789xx1234"), capt: 11, head: 7, tail: 16, valid: true}
+	tests := []struct {
+		name string
+		sum  func(off, n int) (uint32, error)
+		want uint32
+	}{
+		{
+			name: "crc8",
+			sum: func(off, n int) (uint32, error) {
+				s, err := b.CRC8(off, n)
+				return uint32(s), err
+			},
+			want: 0xF4,
+		},
+		{
+			name: "crc16",
+			sum: func(off, n int) (uint32, error) {
+				s, err := b.CRC16(off, n)
+				return uint32(s), err
+			},
+			want: 0x29B1,
+		},
+		{name: "crc32", sum: b.CRC32, want: 0xCBF43926},
+		{
+			name: "fletcher16",
+			sum: func(off, n int) (uint32, error) {
+				s, err := b.Fletcher16(off, n)
+				return uint32(s), err
+			},
+			want: 0x1EDE,
+		},
+		{name: "adler32", sum: b.Adler32, want: 0x091E01DE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := tt.sum(0, 9); got != tt.want || err != nil {
+				t.Errorf("buf.%s() = %#x, %v, want %#x, %v", tt.name, got, err, tt.want, nil)
+			}
+			if _, err := tt.sum(1, 9); err != &nogc.ErrOutOfRange {
+				t.Errorf("buf.%s() error = %v, want %v", tt.name, err, &nogc.ErrOutOfRange)
+			}
+			if _, err := tt.sum(-1, 1); err != &nogc.ErrOutOfRange {
+				t.Errorf("buf.%s() error = %v, want %v", tt.name, err, &nogc.ErrOutOfRange)
+			}
+		})
+	}
+	if got := queued(b); got != "123456789" {
+		t.Errorf("buf.CRC() queued = %q, want %q", got, "123456789")
+	}
+}
+
+func Test_buf_Adler32(t *testing.T) {
+	// Compare against the standard library on a range long enough to require
+	// several reductions, at every offset around the wrap.
+	var r Ring
+	r.Configure(make([]byte, 12000))
+	src := bytes.Repeat([]byte{0xFF, 0xFE, 0x00, 0x80}, 4000)
+	for off := 0; off < 16; off++ {
+		r.Write(src[:off*331])
+		p := src[:11000]
+		r.Write(p)
+		for _, rng := range [][2]int{{0, 11000}, {7, 10000}, {11000, 0}} {
+			q := p[rng[0] : rng[0]+rng[1]]
+			if got, _ := r.Adler32(r.Len()-len(p)+rng[0], rng[1]); got != adler32.Checksum(q) {
+				t.Errorf("buf.Adler32() = %#x, want %#x", got, adler32.Checksum(q))
+			}
+			if got, _ := r.CRC32(r.Len()-len(p)+rng[0], rng[1]); got != crc32.ChecksumIEEE(q) {
+				t.Errorf("buf.CRC32() = %#x, want %#x", got, crc32.ChecksumIEEE(q))
+			}
+		}
+	}
+}
+
+func Test_buf_CRC_Allocs(t *testing.T) {
+	b := &buf{Byte: []byte("56789xx1234"), capt: 11, head: 7, tail: 16, valid: true}
+	allocs := testing.AllocsPerRun(100, func() {
+		b.CRC8(0, 9)
+		b.CRC16(0, 9)
+		b.CRC32(0, 9)
+		b.Fletcher16(0, 9)
+		b.Adler32(0, 9)
+	})
+	if allocs != 0 {
+		t.Errorf("buf.CRC() allocs = %v, want 0", allocs)
+	}
+}
@@ -2,6 +2,7 @@ package seq
 
 import (
 	"io"
+	"math"
 
 	"github.com/ardnew/nogc"
 )
@@ -24,32 +25,84 @@ type Ring struct{ buf }
 
 // buf defines a first-in, first-out (FIFO) queue of bytes.
 type buf struct {
-	Byte  []byte
-	capt  uint32
-	head  uint32
-	tail  uint32
-	mode  mode
-	valid bool
+	Byte    []byte
+	capt    uint32
+	head    uint32 // position of first-in element, in range [0, 2*capt)
+	tail    uint32 // position after last-in element, in range [0, 2*capt)
+	mode    mode
+	shut    bool // closed for writing; io.EOF is returned once empty
+	eof     bool // io.EOF is returned whenever empty, even if not shut
+	stats   Stats
+	low     uint32 // watermark at or below which b is no longer above
+	high    uint32 // watermark at or above which b is above; 0 if disabled
+	above   bool   // whether b is above its watermarks
+	crossed bool   // whether above changed since the last call to Watermark
+	notify  func(above bool)
+	unread  uint32 // bytes read by the last read that may be unread
+	rsize   uint8  // size of the last rune read, if that was the last read
+	valid   bool
 }
 
+// maxCapacity is the maximum capacity of a queue whose positions are tracked
+// with uint32 in the range [0, 2*capacity).
+const maxCapacity = math.MaxInt32
+
 // Configure initializes l using all of p as storage.
 // The initial length of l is 0; any data already in p may be overwritten.
-// The capacity of l is permanently len(p).
+// The capacity of l is permanently len(p), which must be greater than 0 and no
+// greater than math.MaxInt32.
 // Callers must not modify p after initializing.
 func (l *List) Configure(p []byte) (ok bool) {
+	if len(p) > maxCapacity {
+		p = nil
+	}
 	l.valid = l.init(p, uint32(len(p)), retain)
 	return l.valid
 }
 
 // Configure initializes r using all of p as storage.
 // The initial length of r is 0; any data already in p may be overwritten.
-// The capacity of r is permanently len(p).
+// The capacity of r is permanently len(p), which must be greater than 0 and no
+// greater than math.MaxInt32.
 // Callers must not modify p after initializing.
 func (r *Ring) Configure(p []byte) (ok bool) {
+	if len(p) > maxCapacity {
+		p = nil
+	}
 	r.valid = r.init(p, uint32(len(p)), dequeue)
 	return r.valid
 }
 
+// ConfigureWith initializes l using all of p as storage, adopting the first n
+// bytes of p as the initial contents of l, from first-in to last-in.
+// The capacity of l is permanently len(p), which must be greater than 0 and no
+// greater than math.MaxInt32, and n must be in the range [0, len(p)].
+// Callers must not modify p after initializing.
+func (l *List) ConfigureWith(p []byte, n int) (ok bool) {
+	if len(p) > maxCapacity || n < 0 || n > len(p) {
+		p = nil
+	}
+	if l.valid = l.init(p, uint32(len(p)), retain); l.valid {
+		l.fill(uint32(n))
+	}
+	return l.valid
+}
+
+// ConfigureWith initializes r using all of p as storage, adopting the first n
+// bytes of p as the initial contents of r, from first-in to last-in.
+// The capacity of r is permanently len(p), which must be greater than 0 and no
+// greater than math.MaxInt32, and n must be in the range [0, len(p)].
+// Callers must not modify p after initializing.
+func (r *Ring) ConfigureWith(p []byte, n int) (ok bool) {
+	if len(p) > maxCapacity || n < 0 || n > len(p) {
+		p = nil
+	}
+	if r.valid = r.init(p, uint32(len(p)), dequeue); r.valid {
+		r.fill(uint32(n))
+	}
+	return r.valid
+}
+
 // init initializes the configuration.
 func (b *buf) init(p []byte, capacity uint32, mode mode) (ok bool) {
 	if b == nil {
@@ -60,16 +113,77 @@ func (b *buf) init(p []byte, capacity uint32, mode mode) (ok bool) {
 	b.head = 0
 	b.tail = 0
 	b.mode = mode
-	ok = p != nil
+	b.shut = false
+	b.eof = false
+	b.stats = Stats{}
+	b.low = 0
+	b.high = 0
+	b.above = false
+	b.crossed = false
+	b.notify = nil
+	b.unread = 0
+	b.rsize = 0
+	ok = capacity > 0
 	return
 }
 
+// fill sets the contents of a newly initialized b to the first n bytes of its
+// storage.
+func (b *buf) fill(n uint32) {
+	b.tail = n
+	b.stats.HighWater = int(n)
+}
+
+// Migrate moves the contents of b, from first-in to last-in, to the start of p
+// and then uses all of p as storage, so that the capacity of b becomes len(p).
+// Whether b is closed, its Stats, and its watermarks are not changed, but bytes
+// read can no longer be unread.
+//
+// The capacity of p must be greater than 0 and no greater than math.MaxInt32.
+// If p is not large enough for all bytes in b, or for the high watermark of b,
+// returns ErrOutOfRange and b is not modified.
+//
+// Bytes are copied directly without any buffering, so p must not overlap the
+// storage of b. Callers must not modify p after migrating, and b no longer uses
+// its previous storage.
+func (b *buf) Migrate(p []byte) (err error) {
+	if b == nil || !b.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	n := b.distance(b.head, b.tail)
+	if len(p) == 0 || len(p) > maxCapacity ||
+		n > uint32(len(p)) || b.high > uint32(len(p)) {
+		return &nogc.ErrOutOfRange
+	}
+	r1, r2 := b.peekSpans(0, n)
+	copy(p[copy(p, r1):], r2)
+	b.Byte = p
+	b.capt = uint32(len(p))
+	b.head = 0
+	b.tail = n
+	b.unread = 0
+	b.rsize = 0
+	return nil
+}
+
+// index returns the physical array index of the element at position i.
+func (b *buf) index(i uint32) uint32 { return index(b.capt, i) }
+
+// advance returns position i moved forward by n elements.
+func (b *buf) advance(i, n uint32) uint32 { return advance(b.capt, i, n) }
+
+// retreat returns position i moved backward by n elements.
+func (b *buf) retreat(i, n uint32) uint32 { return retreat(b.capt, i, n) }
+
+// distance returns the number of elements from position h to position t.
+func (b *buf) distance(h, t uint32) uint32 { return distance(b.capt, h, t) }
+
 // Len returns the number of bytes.
 func (b *buf) Len() int {
 	if b == nil || !b.valid {
 		return 0
 	}
-	return int(b.tail - b.head)
+	return int(b.distance(b.head, b.tail))
 }
 
 // Cap returns the byte capacity.
@@ -87,10 +201,65 @@ func (b *buf) Reset() {
 	}
 	b.head = 0
 	b.tail = 0
+	b.unread = 0
+	b.rsize = 0
+	b.mark()
+}
+
+// CloseWrite closes b for writing; subsequent writes return ErrClosed.
+// Bytes already in b can still be read, after which reads return io.EOF.
+//
+// A closed b is only reopened by calling Configure.
+func (b *buf) CloseWrite() error {
+	if b == nil || !b.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	b.shut = true
+	return nil
+}
+
+// Close closes b for writing and discards all bytes in b; subsequent writes
+// return ErrClosed and subsequent reads return io.EOF.
+//
+// A closed b is only reopened by calling Configure.
+func (b *buf) Close() error {
+	if b == nil || !b.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	b.shut = true
+	b.head = b.tail
+	b.unread = 0
+	b.rsize = 0
+	b.mark()
+	return nil
+}
+
+// SetEOFOnEmpty sets whether reading from an empty b that is not closed returns
+// io.EOF instead of ErrEmpty. It is false by default.
+//
+// By default, io.EOF means the end of the stream: b is closed and every byte
+// written has been read. ErrEmpty means b is only empty for now, and more bytes
+// may be written later.
+func (b *buf) SetEOFOnEmpty(eof bool) {
+	if b == nil || !b.valid {
+		return
+	}
+	b.eof = eof
+}
+
+// empty returns the error returned by reads from b when it is empty.
+func (b *buf) empty() error {
+	if b.shut || b.eof {
+		return io.EOF
+	}
+	return &nogc.ErrEmpty
 }
 
 // Read copies up to len(p) unread bytes from b to p and returns the number of
 // bytes copied.
+//
+// If b is empty, returns ErrEmpty, or io.EOF if b is closed. Read also returns
+// io.EOF along with the last bytes read from a closed b.
 func (b *buf) Read(p []byte) (n int, err error) {
 	if b == nil || !b.valid {
 		return 0, &nogc.ErrInvalidReceiver
@@ -98,25 +267,36 @@ func (b *buf) Read(p []byte) (n int, err error) {
 	if p == nil {
 		return 0, &nogc.ErrInvalidArgument
 	}
-	var ns int
-	ns, n = b.Len(), len(p)
-	if ns <= n {
-		n, err = ns, io.EOF
-	}
-	h := b.head
-	for i := range p[:n] {
-		p[i] = b.Byte[h%b.capt]
-		h++
+	h, t := b.head, b.tail
+	ns := b.distance(h, t)
+	if uint32(len(p)) < ns {
+		ns = uint32(len(p))
+	} else if ns == 0 && len(p) > 0 {
+		err = b.empty()
+	} else if b.shut || b.eof {
+		err = io.EOF
 	}
-	b.head = h
+	// The elements span at most two contiguous regions of the backing array; the
+	// second region exists only if the elements wrap around the end of it.
+	r1, r2 := spans(b.Byte, b.capt, b.index(h), ns)
+	n = copy(p, r1)
+	n += copy(p[n:], r2)
+	b.head = b.advance(h, uint32(n))
+	b.dequeued(uint32(n))
 	return
 }
 
 // Write appends up to len(p) bytes from p to b and returns the number of bytes
 // copied.
 //
-// Write will only write to the free space in b and then return ErrWriteOverflow
-// if all of p could not be copied.
+// A List will only write to the free space in b and then return
+// ErrWriteOverflow if all of p could not be copied.
+//
+// If b is closed for writing, returns ErrClosed.
+//
+// A Ring always copies all of p, dequeuing the oldest bytes in b as needed to
+// make room. If len(p) exceeds the capacity of b, only the last Cap() bytes of
+// p are retained.
 func (b *buf) Write(p []byte) (n int, err error) {
 	if b == nil || !b.valid {
 		return 0, &nogc.ErrInvalidReceiver
@@ -124,6 +304,15 @@ func (b *buf) Write(p []byte) (n int, err error) {
 	if p == nil {
 		return 0, &nogc.ErrInvalidArgument
 	}
+	return write(b, p)
+}
+
+// write implements Write for p of either []byte or string, so that strings can
+// be written without first converting them to []byte.
+func write[T []byte | string](b *buf, p T) (n int, err error) {
+	if b.shut {
+		return 0, &nogc.ErrClosed
+	}
 	np := len(p)
 	if np == 0 {
 		// Source buffer is empty; there are no bytes from p to copy into b.
@@ -131,15 +320,37 @@ func (b *buf) Write(p []byte) (n int, err error) {
 		return
 	}
 	h, t := b.head, b.tail
-	for _, c := range p {
-		if t-h >= b.capt {
-			break
+	if b.mode == dequeue && np > int(b.capt) {
+		// Only the last capt bytes of p can be retained in b, and they will replace
+		// every byte currently in b. Skip over the leading bytes of p as though
+		// they had been written and then immediately dequeued.
+		n = np - int(b.capt)
+		t = b.advance(t, uint32(n))
+		h = t
+		p = p[n:]
+	}
+	nw, nf := uint32(len(p)), b.capt-b.distance(h, t)
+	if nw > nf {
+		if b.mode == retain {
+			nw = nf
+		} else {
+			// Dequeue the oldest bytes to make room for all of p.
+			h = b.advance(h, nw-nf)
 		}
-		b.Byte[t%b.capt] = c
-		t++
-		n++
 	}
-	b.tail = t
+	// The free space spans at most two contiguous regions of the backing array;
+	// the second region exists only if the free space wraps around the end of it.
+	r1, r2 := spans(b.Byte, b.capt, b.index(t), nw)
+	nc := copy(r1, p)
+	nc += copy(r2, p[nc:])
+	n += nc
+	ns := b.distance(b.head, b.tail)
+	b.head = h
+	b.tail = b.advance(t, uint32(nc))
+	// Every byte of p that is not in b after the write was dropped, whether it
+	// was rejected by a List or it overwrote (or was overwritten in) a Ring.
+	b.enqueued(uint32(np))
+	b.dropped(ns + uint32(np) - b.distance(b.head, b.tail))
 	if n < np {
 		err = &nogc.ErrWriteOverflow
 	}
@@ -166,7 +377,8 @@ func (b *buf) readFrom(r io.Reader, lo, hi int) (n int, err error) {
 		err = nil
 	}
 	// Extend the length of b by the number of bytes copied.
-	b.tail += uint32(n)
+	b.tail = b.advance(b.tail, uint32(n))
+	b.enqueued(uint32(n))
 	return
 }
 
@@ -177,6 +389,16 @@ func (b *buf) readFrom(r io.Reader, lo, hi int) (n int, err error) {
 // ReadFrom is defined to read from r until all bytes have been read (io.EOF),
 // so it does not treat io.EOF from r as an error to be reported.
 //
+// If b is closed for writing, returns ErrClosed.
+// A List returns ErrReadOverflow if it is already full. A Ring continues to
+// read from r until io.EOF, dequeuing the oldest bytes as needed to make room,
+// so that it retains the last Cap() bytes read from r. If r implements
+// io.WriterTo, a Ring copies from r by calling r.WriteTo. Otherwise, since r
+// may use all of the space it is given as scratch space, a full Ring dequeues
+// the oldest bytes before each read from r, starting with one byte and doubling
+// while r fills the space it is given, so the Ring retains fewer bytes if r
+// then returns fewer, e.g. on its final read.
+//
 // Bytes are copied directly without any buffering, so r and b must not overlap
 // if both are implemented as buffers of physical memory.
 func (b *buf) ReadFrom(r io.Reader) (n int64, err error) {
@@ -186,63 +408,112 @@ func (b *buf) ReadFrom(r io.Reader) (n int64, err error) {
 	if r == nil {
 		return 0, &nogc.ErrInvalidArgument
 	}
+	if b.shut {
+		return 0, &nogc.ErrClosed
+	}
+	if b.mode == dequeue {
+		if wt, ok := r.(io.WriterTo); ok {
+			// Let r copy from its own storage with Write, which never needs to
+			// dequeue bytes before they are replaced.
+			return wt.WriteTo(b)
+		}
+		return b.readFromRing(r)
+	}
 	h, t := b.head, b.tail
-	// Convert head and tail to physical array indices to determine if the used
-	// elements span a contiguous region of memory in the backing array.
-	ih, it := h%b.capt, t%b.capt
-	// If the array indices are equal, with head not eqaul to tail, then the
-	// backing array is filled to capacity. We have nowhere to store the bytes
-	// from r. We can either overwrite the existing buffer or retain it and return
-	// an error. Opting for the latter so that no bytes are lost, and it gives the
-	// caller an opportunity to remedy the situation.
-	if h != t && ih == it {
+	// If the number of elements equals capacity, then the backing array is filled
+	// to capacity. We have nowhere to store the bytes from r. We can either
+	// overwrite the existing buffer or retain it and return an error. Opting for
+	// the latter so that no bytes are lost, and it gives the caller an
+	// opportunity to remedy the situation.
+	nf := b.capt - b.distance(h, t)
+	if nf == 0 {
+		// No bytes were read from r, so none were written or dropped, but the read
+		// was still rejected.
+		b.stats.Overflows++
 		return 0, &nogc.ErrReadOverflow
 	}
-	// When first-in (head) element is located at index 0 (i.e., the start of the
-	// backing array), then the unused space spans exactly one region only;
-	// namely, the range from last-in (tail) element to end of the array:
+	// The unused elements span from the last-in (tail) element to the first-in
+	// (head) element, potentially in two separate contiguous regions of the
+	// backing array. The first region (1) spans from tail to either head or the
+	// end of the array, and the second region (2) spans from the start of the
+	// array to head only if tail has not yet wrapped around:
 	//   (0123456789A) === Array index reference
-	//   [HxxxT......]     Free-space forms contiguous span [4..A]
-	if ih == 0 {
-		nr, errr := b.readFrom(r, int(it), int(b.capt))
-		return int64(nr), errr
+	//   [HxxxT......]     Free-space in region 1 [4..A] only
+	//   [...HxxxT...]     Free-space in region 1 [7..A] and region 2 [0..2]
+	//   [xxT......Hx]     Free-space in region 1 [2..8] only
+	//   [T......Hxxx]     Free-space in region 1 [0..6] only
+	it := b.index(t)
+	r1, r2 := spans(b.Byte, b.capt, it, nf)
+	// (1.) Copy into tail to end of region 1.
+	n1, err1 := b.readFrom(r, int(it), int(it)+len(r1))
+	// Region 2 is contiguous with region 1 only if region 1 was filled, because
+	// tail will then have wrapped around to the start of the backing array.
+	if err1 != nil || n1 < len(r1) || len(r2) == 0 {
+		return int64(n1), err1
 	}
-	// Tail grows as elements are added to the ring buffer. Thus, if tail is less
-	// than head, then the tail index has wrapped around after growing beyond the
-	// backing array's high index (capacity-1), but the head index has not yet
-	// wrapped around.
-	if it > ih {
-		// Tail has not overflowed its storage prior to head, which is the normal
-		// case, and thus the unused elements potentially exist in two separate
-		// contiguous regions of the backing array. The first region (1) spans from
-		// the last-in (tail) element to the end of the array, and the second
-		// region (2) spans from the start of the array to the first-in (head)
-		// element:
+	// (2.) Copy into start of the backing array to head.
+	n2, err2 := b.readFrom(r, 0, len(r2))
+	return int64(n1 + n2), err2
+}
+
+func (b *buf) readFromRing(r io.Reader) (n int64, err error) {
+	// step is the number of the oldest bytes dequeued for the next read once b is
+	// full. It starts at one byte and doubles while r fills the region it is
+	// given, so that a short read, e.g. the final read returning io.EOF, costs at
+	// most twice the bytes returned by the previous read.
+	step := uint32(1)
+	for {
+		// r may use all of the region it is given as scratch space, even if it
+		// returns fewer bytes, so the region never holds bytes that are retained.
+		// If there is free space, the region spans from last-in (tail) to either
+		// first-in (head) or the end of the array. Otherwise, it spans from tail
+		// toward the end of the array, and the oldest bytes it holds are dequeued
+		// first:
 		//   (0123456789A) === Array index reference
-		//   [...HxxxT...]     Free-space in region 1 [7..A] and region 2 [0..2]
-		//   [......HxxxT]     Free-space in region 1 (A) and region 2 [0..5]
-		var (
-			n1, n2     int
-			err1, err2 error
-		)
-		// (1.) Copy into tail to end of the backing array
-		if n1, err1 = b.readFrom(r, int(it), int(b.capt)); err1 != nil {
-			return int64(n1), err1
+		//   [xxT......Hx]     Writable region spans [2..8]
+		//   [xxxxxxHxxxT]     Writable region spans (A), after dequeuing (A)
+		//   [xxxxxxxxxxT]     Writable region spans [0..3], after dequeuing [0..3]
+		it := b.index(b.tail)
+		nw := b.capt - it
+		nf := b.capt - b.distance(b.head, b.tail)
+		if nf > 0 && nf < nw {
+			nw = nf
+		} else if nf == 0 {
+			if step < nw {
+				nw = step
+			}
+			b.head = b.advance(b.head, nw)
+			b.dropped(nw)
 		}
-		// (2.) Copy into start of the backing array to head (if region length > 0).
-		if ih > 0 {
-			n2, err2 = b.readFrom(r, 0, int(ih))
+		nr, errr := r.Read(b.Byte[it : it+nw])
+		n += int64(nr)
+		if nf == 0 {
+			step = nextStep(nw, uint32(nr))
+		}
+		b.tail = b.advance(b.tail, uint32(nr))
+		b.enqueued(uint32(nr))
+		if errr != nil {
+			// Catch any attempt to return io.EOF and return nil instead.
+			// See documentation on io.ReaderFrom, and io.Copy.
+			if errr == io.EOF {
+				errr = nil
+			}
+			return n, errr
 		}
-		return int64(n1 + n2), err2
 	}
-	// Unused elements form contiguous span in backing array from last-in (tail)
-	// to first-in (head), which may include the entire front of the backing array
-	// when tail is exactly equal to a multiple of array capacity.
-	//   (0123456789A) === Array index reference
-	//   [xxT......Hx]     Free-space forms contiguous span [2..8]
-	//   [T......Hxxx]     Free-space forms contiguous span [0..6]
-	nr, errr := b.readFrom(r, int(it), int(ih))
-	return int64(nr), errr
+}
+
+// nextStep returns the number of the oldest bytes to dequeue from a full Ring
+// before the next read from a reader that returned n of the step bytes it was
+// given by the previous read.
+func nextStep(step, n uint32) uint32 {
+	switch {
+	case n == step:
+		return step << 1
+	case n > 0:
+		return n
+	}
+	return 1
 }
 
 func (b *buf) writeTo(w io.Writer, lo, hi int) (n int, err error) {
@@ -259,13 +530,18 @@ func (b *buf) writeTo(w io.Writer, lo, hi int) (n int, err error) {
 	}
 	n, err = w.Write(b.Byte[lo:hi])
 	// Decrease length by the number of bytes copied.
-	b.head += uint32(n)
+	b.head = b.advance(b.head, uint32(n))
+	b.dequeued(uint32(n))
 	return
 }
 
 // WriteTo copies bytes from b to w until all bytes have been written or an
 // error was encountered. Returns the number of bytes successfully copied.
 //
+// If b is empty, returns ErrEmpty, unless b is closed, in which case there is
+// nothing more to write and WriteTo returns nil. If SetEOFOnEmpty is enabled,
+// returns io.EOF instead.
+//
 // Bytes are copied directly without any buffering, so w and b must not overlap
 // if both are implemented as buffers of physical memory.
 func (b *buf) WriteTo(w io.Writer) (n int64, err error) {
@@ -278,11 +554,14 @@ func (b *buf) WriteTo(w io.Writer) (n int64, err error) {
 	h, t := b.head, b.tail
 	if h == t {
 		// Buffer is empty, writing zero bytes to w.
-		return 0, io.EOF
+		if b.shut && !b.eof {
+			return 0, nil
+		}
+		return 0, b.empty()
 	}
 	// Convert head and tail to physical array indices to determine if the used
 	// elements span a contiguous region of memory in the backing array.
-	ih, it := h%b.capt, t%b.capt
+	ih, it := b.index(h), b.index(t)
 	// Tail grows as elements are added to the ring buffer. Thus, if tail is less
 	// than head, then the tail index has wrapped around after growing beyond the
 	// backing array's high index (capacity-1), but the head index has not yet
@@ -308,6 +587,8 @@ func (b *buf) WriteTo(w io.Writer) (n int64, err error) {
 		}
 		// (2.) Copy from start of the backing array to tail.
 		n2, err2 := b.writeTo(w, 0, int(it))
+		// Both phases are a single read; either phase can be unread.
+		b.unread = uint32(n1 + n2)
 		return int64(n1 + n2), err2
 	}
 	// Elements form contiguous span in backing array from first-in (head) element
@@ -320,7 +601,7 @@ func (b *buf) WriteTo(w io.Writer) (n int64, err error) {
 }
 
 // ReadByte returns the next unread byte from b and a nil error.
-// If b is empty, returns 0, io.EOF.
+// If b is empty, returns 0, ErrEmpty, or 0, io.EOF if b is closed.
 //
 // To avoid ambiguous validity of the returned byte, ReadByte will always return
 // either a valid byte and nil error, or an invalid byte and non-nil error.
@@ -331,46 +612,164 @@ func (b *buf) ReadByte() (c byte, err error) {
 	}
 	h, t := b.head, b.tail
 	if h == t {
-		// Reading zero bytes from b (empty), return io.EOF.
-		return 0, io.EOF
+		// Reading zero bytes from b (empty), return ErrEmpty or io.EOF.
+		return 0, b.empty()
 	}
 	// Reading 1 byte from b, reduce length by 1.
-	b.head++
+	b.head = b.advance(h, 1)
+	b.dequeued(1)
 	// Return the byte from original head position.
-	return b.Byte[h%b.capt], nil
+	return b.Byte[b.index(h)], nil
 }
 
 // UnreadByte causes the next call to ReadByte to return the last byte read.
 // If the last operation was not a successful call to ReadByte, UnreadByte will
 // unread the last byte read.
+//
+// UnreadByte may be called repeatedly to unread each of the bytes read by the
+// last method called on b that reads bytes, such as Read or Discard. If there
+// are no such bytes left to unread, or if any method that writes bytes or Reset
+// has been called since, returns ErrInvalidUnread.
 func (b *buf) UnreadByte() error {
 	if b == nil || !b.valid {
 		return &nogc.ErrInvalidReceiver
 	}
-	if b.head > 0 {
-		b.head--
+	if b.unread == 0 {
+		return &nogc.ErrInvalidUnread
 	}
+	b.rewind(1)
 	return nil
 }
 
+// Unread causes up to the last n bytes read to be returned again by the next
+// read, and returns the number of bytes unread.
+//
+// Only the bytes read by the last method called on b that reads bytes, such as
+// Read or Discard, can be unread, since any other bytes may have been
+// overwritten. If fewer than n bytes can be unread, Unread unreads as many as it
+// can and returns ErrInvalidUnread. If any method that writes bytes or Reset
+// has been called since the last read, no bytes can be unread.
+func (b *buf) Unread(n int) (unread int, err error) {
+	if b == nil || !b.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if n < 0 {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	if unread = n; uint32(n) > b.unread {
+		unread, err = int(b.unread), &nogc.ErrInvalidUnread
+	}
+	b.rewind(uint32(unread))
+	return
+}
+
+// rewind moves head backward by n bytes, which must be no greater than the
+// number of bytes that can be unread.
+func (b *buf) rewind(n uint32) {
+	if n == 0 {
+		return
+	}
+	b.head = b.retreat(b.head, n)
+	b.unread -= n
+	b.rsize = 0
+	if b.stats.Read >= uint64(n) {
+		b.stats.Read -= uint64(n)
+	}
+	b.mark()
+}
+
 // WriteByte appends c to b and returns nil.
-// If b is full, returns ErrWriteOverflow.
+// If b is full, a List returns ErrWriteOverflow, and a Ring dequeues the oldest
+// byte to make room for c.
+// If b is closed for writing, returns ErrClosed.
 func (b *buf) WriteByte(c byte) (err error) {
 	if b == nil || !b.valid {
 		return &nogc.ErrInvalidReceiver
 	}
+	if b.shut {
+		return &nogc.ErrClosed
+	}
 	h, t := b.head, b.tail
-	ih, it := h%b.capt, t%b.capt
+	ih, it := b.index(h), b.index(t)
 	// If the array indices are equal, with head not eqaul to tail, then the
 	// backing array is filled to capacity. We have nowhere to store the byte.
-	// We can either discard head or retain it and return an error. Opting for
-	// the latter so that no byte is lost, and it gives the caller an opportunity
-	// to remedy the situation.
+	// A List retains head and returns an error so that no byte is lost, and it
+	// gives the caller an opportunity to remedy the situation. A Ring discards
+	// head, whose position in the backing array is then reused for c.
 	if h != t && ih == it {
-		return &nogc.ErrWriteOverflow
+		if b.mode == retain {
+			b.enqueued(1)
+			b.dropped(1)
+			return &nogc.ErrWriteOverflow
+		}
+		b.head = b.advance(h, 1)
+		b.dropped(1)
 	}
 	// Write the byte into tail position and increment length by 1.
 	b.Byte[it] = c
-	b.tail++
+	b.tail = b.advance(t, 1)
+	b.enqueued(1)
 	return nil
 }
+
+// The positions of the first-in (head) and last-in (tail) elements of a queue
+// with capacity capt are tracked in the range [0, 2*capt), which is twice the
+// range of physical array indices. Thus, a full queue, whose head and tail have
+// equal array indices but unequal positions, is distinguishable from an empty
+// queue, whose head and tail positions are equal.
+//
+// Positions are never allowed to grow without bound, since reducing them modulo
+// capt would be incorrect once they overflow (unless capt is a power of 2).
+
+// index returns the physical array index of the element at position i.
+func index(capt, i uint32) uint32 {
+	if i >= capt {
+		return i - capt
+	}
+	return i
+}
+
+// advance returns position i moved forward by n elements.
+func advance(capt, i, n uint32) uint32 {
+	m := uint64(capt) << 1
+	j := uint64(i) + uint64(n)
+	if j >= m {
+		if j -= m; j >= m {
+			j %= m
+		}
+	}
+	return uint32(j)
+}
+
+// retreat returns position i moved backward by n elements.
+func retreat(capt, i, n uint32) uint32 {
+	m := capt << 1
+	if n >= m {
+		n %= m
+	}
+	if i < n {
+		return i + (m - n)
+	}
+	return i - n
+}
+
+// distance returns the number of elements from position h to position t.
+func distance(capt, h, t uint32) uint32 {
+	if t < h {
+		return (capt << 1) - h + t
+	}
+	return t - h
+}
+
+// spans returns the one or two contiguous regions of p, whose length is capt,
+// that together form the n elements starting at physical array index i.
+// The second region is empty if the elements do not wrap around the end of p.
+func spans(p []byte, capt, i, n uint32) (a, b []byte) {
+	if n == 0 {
+		return nil, nil
+	}
+	if i+n <= capt {
+		return p[i : i+n], nil
+	}
+	return p[i:capt], p[:i+n-capt]
+}
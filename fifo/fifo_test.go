@@ -3,9 +3,23 @@ package seq
 import (
 	"bytes"
 	"io"
+	"math"
+	"strings"
 	"testing"
+	"testing/iotest"
+
+	"github.com/ardnew/nogc"
 )
 
+// queued returns the bytes in b without dequeuing them.
+func queued(b *buf) string {
+	var s strings.Builder
+	for h := b.head; h != b.tail; h = b.advance(h, 1) {
+		s.WriteByte(b.Byte[b.index(h)])
+	}
+	return s.String()
+}
+
 func TestList_Configure(t *testing.T) {
 	type fields struct {
 		buf buf
@@ -60,6 +74,48 @@ func TestRing_Configure(t *testing.T) {
 	}
 }
 
+func TestList_ConfigureWith(t *testing.T) {
+	tests := []struct {
+		name   string
+		p      []byte
+		n      int
+		wantOk bool
+		wantB  string
+	}{
+		{name: "empty", p: []byte("abcd"), n: 0, wantOk: true, wantB: ""},
+		{name: "some", p: []byte("abcd"), n: 2, wantOk: true, wantB: "ab"},
+		{name: "full", p: []byte("abcd"), n: 4, wantOk: true, wantB: "abcd"},
+		{name: "negative", p: []byte("abcd"), n: -1, wantOk: false, wantB: ""},
+		{name: "too-long", p: []byte("abcd"), n: 5, wantOk: false, wantB: ""},
+		{name: "nil", p: nil, n: 0, wantOk: false, wantB: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l List
+			if gotOk := l.ConfigureWith(tt.p, tt.n); gotOk != tt.wantOk {
+				t.Errorf("List.ConfigureWith() = %v, want %v", gotOk, tt.wantOk)
+			}
+			if got := queued(&l.buf); got != tt.wantB {
+				t.Errorf("List.ConfigureWith() queued = %q, want %q", got, tt.wantB)
+			}
+			if got := l.Stats().HighWater; got != len(tt.wantB) {
+				t.Errorf("List.ConfigureWith() HighWater = %v, want %v", got, len(tt.wantB))
+			}
+		})
+	}
+}
+
+func TestRing_ConfigureWith(t *testing.T) {
+	var r Ring
+	if !r.ConfigureWith([]byte("abcd"), 4) {
+		t.Fatalf("Ring.ConfigureWith() = %v, want %v", false, true)
+	}
+	r.WriteByte('e')
+	if got := queued(&r.buf); got != "bcde" {
+		t.Errorf("Ring.ConfigureWith() queued = %q, want %q", got, "bcde")
+	}
+}
+
 func Test_buf_init(t *testing.T) {
 	type fields struct {
 		Byte  []byte
@@ -99,6 +155,50 @@ func Test_buf_init(t *testing.T) {
 	}
 }
 
+func Test_buf_Migrate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    mode
+		capt    int
+		high    int
+		wantErr error
+		wantB   string
+		wantCap int
+	}{
+		{name: "grow", capt: 8, wantB: "cdef", wantCap: 8},
+		{name: "exact", capt: 4, wantB: "cdef", wantCap: 4},
+		{name: "ring", mode: dequeue, capt: 6, wantB: "cdef", wantCap: 6},
+		{name: "short", capt: 3, wantErr: &nogc.ErrOutOfRange, wantB: "cdef", wantCap: 5},
+		{name: "empty", capt: 0, wantErr: &nogc.ErrOutOfRange, wantB: "cdef", wantCap: 5},
+		{name: "watermark", capt: 4, high: 5, wantErr: &nogc.ErrOutOfRange, wantB: "cdef", wantCap: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &buf{}
+			b.valid = b.init(make([]byte, 5), 5, tt.mode)
+			b.WriteString("abc")
+			b.Read(make([]byte, 2))
+			b.WriteString("def")
+			b.CloseWrite()
+			if tt.high > 0 {
+				b.SetWatermarks(1, tt.high, nil)
+			}
+			if err := b.Migrate(make([]byte, tt.capt)); err != tt.wantErr {
+				t.Errorf("buf.Migrate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := queued(b); got != tt.wantB {
+				t.Errorf("buf.Migrate() queued = %q, want %q", got, tt.wantB)
+			}
+			if got := b.Cap(); got != tt.wantCap {
+				t.Errorf("buf.Migrate() Cap = %v, want %v", got, tt.wantCap)
+			}
+			if err := b.WriteByte('g'); err != &nogc.ErrClosed {
+				t.Errorf("buf.WriteByte() error = %v, want %v", err, &nogc.ErrClosed)
+			}
+		})
+	}
+}
+
 func Test_buf_Len(t *testing.T) {
 	type fields struct {
 		Byte  []byte
@@ -202,6 +302,8 @@ func Test_buf_Read(t *testing.T) {
 		head  uint32
 		tail  uint32
 		mode  mode
+		shut  bool
+		eof   bool
 		valid bool
 	}
 	type args struct {
@@ -212,9 +314,57 @@ func Test_buf_Read(t *testing.T) {
 		fields  fields
 		args    args
 		wantN   int
-		wantErr bool
+		wantP   string
+		wantErr error
 	}{
-		// TODO: Add test cases.
+		{
+			name:   "partial",
+			fields: fields{Byte: []byte("abcd"), capt: 4, head: 0, tail: 4, valid: true},
+			args:   args{p: make([]byte, 3)},
+			wantN:  3, wantP: "abc",
+		},
+		{
+			name:   "drain",
+			fields: fields{Byte: []byte("abcd"), capt: 4, head: 1, tail: 3, valid: true},
+			args:   args{p: make([]byte, 3)},
+			wantN:  2, wantP: "bc",
+		},
+		{
+			name:   "drain-closed",
+			fields: fields{Byte: []byte("abcd"), capt: 4, head: 1, tail: 3, shut: true, valid: true},
+			args:   args{p: make([]byte, 3)},
+			wantN:  2, wantP: "bc", wantErr: io.EOF,
+		},
+		{
+			name:   "drain-eof",
+			fields: fields{Byte: []byte("abcd"), capt: 4, head: 1, tail: 3, eof: true, valid: true},
+			args:   args{p: make([]byte, 3)},
+			wantN:  2, wantP: "bc", wantErr: io.EOF,
+		},
+		{
+			name:   "empty",
+			fields: fields{Byte: []byte("abcd"), capt: 4, head: 3, tail: 3, valid: true},
+			args:   args{p: make([]byte, 3)},
+			wantN:  0, wantP: "", wantErr: &nogc.ErrEmpty,
+		},
+		{
+			name:   "empty-closed",
+			fields: fields{Byte: []byte("abcd"), capt: 4, head: 3, tail: 3, shut: true, valid: true},
+			args:   args{p: make([]byte, 3)},
+			wantN:  0, wantP: "", wantErr: io.EOF,
+		},
+		{
+			name:   "empty-eof",
+			fields: fields{Byte: []byte("abcd"), capt: 4, head: 3, tail: 3, eof: true, valid: true},
+			args:   args{p: make([]byte, 3)},
+			wantN:  0, wantP: "", wantErr: io.EOF,
+		},
+		{
+			name:   "wrapped",
+			fields: fields{Byte: []byte("defabc"), capt: 6, head: 9, tail: 3, valid: true},
+			args:   args{p: make([]byte, 5)},
+			wantN:  5, wantP: "abcde",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -224,16 +374,21 @@ func Test_buf_Read(t *testing.T) {
 				head:  tt.fields.head,
 				tail:  tt.fields.tail,
 				mode:  tt.fields.mode,
+				shut:  tt.fields.shut,
+				eof:   tt.fields.eof,
 				valid: tt.fields.valid,
 			}
 			gotN, err := b.Read(tt.args.p)
-			if (err != nil) != tt.wantErr {
+			if err != tt.wantErr {
 				t.Errorf("buf.Read() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if gotN != tt.wantN {
 				t.Errorf("buf.Read() = %v, want %v", gotN, tt.wantN)
 			}
+			if gotP := string(tt.args.p[:gotN]); gotP != tt.wantP {
+				t.Errorf("buf.Read() = %q, want %q", gotP, tt.wantP)
+			}
 		})
 	}
 }
@@ -255,9 +410,33 @@ func Test_buf_Write(t *testing.T) {
 		fields  fields
 		args    args
 		wantN   int
+		wantB   string
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name:   "list-partial",
+			fields: fields{Byte: []byte("ab.."), capt: 4, head: 0, tail: 2, mode: retain, valid: true},
+			args:   args{p: []byte("cdef")},
+			wantN:  2, wantB: "abcd", wantErr: true,
+		},
+		{
+			name:   "ring-overwrite",
+			fields: fields{Byte: []byte("ab.."), capt: 4, head: 0, tail: 2, mode: dequeue, valid: true},
+			args:   args{p: []byte("cdef")},
+			wantN:  4, wantB: "cdef",
+		},
+		{
+			name:   "ring-overwrite-wrapped",
+			fields: fields{Byte: []byte("d.bc"), capt: 4, head: 2, tail: 5, mode: dequeue, valid: true},
+			args:   args{p: []byte("ef")},
+			wantN:  2, wantB: "cdef",
+		},
+		{
+			name:   "ring-exceeds-capacity",
+			fields: fields{Byte: []byte("xyz."), capt: 4, head: 1, tail: 3, mode: dequeue, valid: true},
+			args:   args{p: []byte("abcdefghij")},
+			wantN:  10, wantB: "ghij",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -277,6 +456,9 @@ func Test_buf_Write(t *testing.T) {
 			if gotN != tt.wantN {
 				t.Errorf("buf.Write() = %v, want %v", gotN, tt.wantN)
 			}
+			if gotB := queued(b); gotB != tt.wantB {
+				t.Errorf("buf.Write() queued = %q, want %q", gotB, tt.wantB)
+			}
 		})
 	}
 }
@@ -326,6 +508,22 @@ func Test_buf_readFrom(t *testing.T) {
 	}
 }
 
+// scratchReader is an io.Reader that uses all of p as scratch space, as
+// io.Reader permits, before copying as much of s as fits into p.
+type scratchReader struct{ s string }
+
+func (r *scratchReader) Read(p []byte) (n int, err error) {
+	for i := range p {
+		p[i] = '#'
+	}
+	if len(r.s) == 0 {
+		return 0, io.EOF
+	}
+	n = copy(p, r.s)
+	r.s = r.s[n:]
+	return n, nil
+}
+
 func Test_buf_ReadFrom(t *testing.T) {
 	type fields struct {
 		Byte  []byte
@@ -343,9 +541,66 @@ func Test_buf_ReadFrom(t *testing.T) {
 		fields  fields
 		args    args
 		wantN   int64
+		wantB   string
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name:   "list-full",
+			fields: fields{Byte: []byte("abcd"), capt: 4, head: 0, tail: 4, mode: retain, valid: true},
+			args:   args{r: strings.NewReader("ef")},
+			wantN:  0, wantB: "abcd", wantErr: true,
+		},
+		{
+			name:   "list-empty-offset",
+			fields: fields{Byte: []byte("...."), capt: 4, head: 6, tail: 6, mode: retain, valid: true},
+			args:   args{r: strings.NewReader("abcdef")},
+			wantN:  4, wantB: "abcd",
+		},
+		{
+			name:   "list-wrapped-short-read",
+			fields: fields{Byte: []byte(".ab..."), capt: 6, head: 1, tail: 3, mode: retain, valid: true},
+			args:   args{r: iotest.HalfReader(strings.NewReader("cdefgh"))},
+			wantN:  2, wantB: "abcd",
+		},
+		{
+			name:   "list-wrapped",
+			fields: fields{Byte: []byte(".ab..."), capt: 6, head: 1, tail: 3, mode: retain, valid: true},
+			args:   args{r: strings.NewReader("cdefgh")},
+			wantN:  4, wantB: "abcdef",
+		},
+		{
+			name:   "ring-full",
+			fields: fields{Byte: []byte("abcd"), capt: 4, head: 0, tail: 4, mode: dequeue, valid: true},
+			args:   args{r: strings.NewReader("ef")},
+			wantN:  2, wantB: "cdef",
+		},
+		{
+			name:   "ring-exceeds-capacity",
+			fields: fields{Byte: []byte("a..."), capt: 4, head: 0, tail: 1, mode: dequeue, valid: true},
+			args:   args{r: iotest.OneByteReader(strings.NewReader("bcdefghij"))},
+			// The final read, which returns io.EOF, is given one of the oldest bytes.
+			wantN: 9, wantB: "hij",
+		},
+		{
+			name:   "list-scratch",
+			fields: fields{Byte: []byte("cdefgh.."), capt: 8, head: 0, tail: 6, mode: retain, valid: true},
+			args:   args{r: &scratchReader{s: "X"}},
+			wantN:  1, wantB: "cdefghX",
+		},
+		{
+			name:   "ring-scratch",
+			fields: fields{Byte: []byte("cdefgh.."), capt: 8, head: 0, tail: 6, mode: dequeue, valid: true},
+			args:   args{r: &scratchReader{s: "X"}},
+			wantN:  1, wantB: "cdefghX",
+		},
+		{
+			name:   "ring-full-scratch",
+			fields: fields{Byte: []byte("efghabcd"), capt: 8, head: 4, tail: 12, mode: dequeue, valid: true},
+			args:   args{r: &scratchReader{s: "XY"}},
+			// One byte is dequeued before the first read, and two before the second,
+			// since r may overwrite all of the bytes it is given.
+			wantN: 2, wantB: "defghXY",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -365,6 +620,9 @@ func Test_buf_ReadFrom(t *testing.T) {
 			if gotN != tt.wantN {
 				t.Errorf("buf.ReadFrom() = %v, want %v", gotN, tt.wantN)
 			}
+			if gotB := queued(b); gotB != tt.wantB {
+				t.Errorf("buf.ReadFrom() queued = %q, want %q", gotB, tt.wantB)
+			}
 		})
 	}
 }
@@ -501,6 +759,121 @@ func Test_buf_ReadByte(t *testing.T) {
 	}
 }
 
+func Test_buf_UnreadByte(t *testing.T) {
+	tests := []struct {
+		name    string
+		ops     func(b *buf)
+		wantErr error
+		wantB   string
+	}{
+		{
+			name:    "none",
+			ops:     func(b *buf) {},
+			wantErr: &nogc.ErrInvalidUnread, wantB: "abcd",
+		},
+		{
+			name:  "readbyte",
+			ops:   func(b *buf) { b.ReadByte() },
+			wantB: "abcd",
+		},
+		{
+			name:  "read",
+			ops:   func(b *buf) { b.Read(make([]byte, 2)) },
+			wantB: "bcd",
+		},
+		{
+			name:    "read-twice",
+			ops:     func(b *buf) { b.ReadByte(); b.UnreadByte() },
+			wantErr: &nogc.ErrInvalidUnread, wantB: "abcd",
+		},
+		{
+			name:    "write",
+			ops:     func(b *buf) { b.ReadByte(); b.WriteByte('e') },
+			wantErr: &nogc.ErrInvalidUnread, wantB: "bcde",
+		},
+		{
+			name:    "reset",
+			ops:     func(b *buf) { b.ReadByte(); b.Reset() },
+			wantErr: &nogc.ErrInvalidUnread, wantB: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &buf{Byte: []byte("dabc"), capt: 4, head: 1, tail: 5, mode: dequeue, valid: true}
+			tt.ops(b)
+			if err := b.UnreadByte(); err != tt.wantErr {
+				t.Errorf("buf.UnreadByte() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := queued(b); got != tt.wantB {
+				t.Errorf("buf.UnreadByte() queued = %q, want %q", got, tt.wantB)
+			}
+		})
+	}
+}
+
+func Test_buf_Unread(t *testing.T) {
+	tests := []struct {
+		name    string
+		ops     func(b *buf)
+		n       int
+		wantN   int
+		wantErr error
+		wantB   string
+	}{
+		{
+			name: "read-all",
+			ops:  func(b *buf) { b.Read(make([]byte, 3)) },
+			n:    3, wantN: 3, wantB: "abcd",
+		},
+		{
+			name: "read-some",
+			ops:  func(b *buf) { b.Read(make([]byte, 3)) },
+			n:    2, wantN: 2, wantB: "bcd",
+		},
+		{
+			name: "read-more",
+			ops:  func(b *buf) { b.Read(make([]byte, 3)) },
+			n:    4, wantN: 3, wantErr: &nogc.ErrInvalidUnread, wantB: "abcd",
+		},
+		{
+			name: "wrapped",
+			ops:  func(b *buf) { b.WriteTo(&bytes.Buffer{}) },
+			n:    4, wantN: 4, wantB: "abcd",
+		},
+		{
+			name: "discard",
+			ops:  func(b *buf) { b.Discard(2) },
+			n:    2, wantN: 2, wantB: "abcd",
+		},
+		{
+			name: "overwritten",
+			ops:  func(b *buf) { b.Read(make([]byte, 3)); b.Write([]byte("efg")) },
+			n:    1, wantN: 0, wantErr: &nogc.ErrInvalidUnread, wantB: "defg",
+		},
+		{
+			name: "negative",
+			ops:  func(b *buf) { b.Read(make([]byte, 3)) },
+			n:    -1, wantN: 0, wantErr: &nogc.ErrInvalidArgument, wantB: "d",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &buf{Byte: []byte("cdab"), capt: 4, head: 2, tail: 6, mode: dequeue, valid: true}
+			tt.ops(b)
+			gotN, err := b.Unread(tt.n)
+			if err != tt.wantErr {
+				t.Errorf("buf.Unread() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotN != tt.wantN {
+				t.Errorf("buf.Unread() = %v, want %v", gotN, tt.wantN)
+			}
+			if got := queued(b); got != tt.wantB {
+				t.Errorf("buf.Unread() queued = %q, want %q", got, tt.wantB)
+			}
+		})
+	}
+}
+
 func Test_buf_WriteByte(t *testing.T) {
 	type fields struct {
 		Byte  []byte
@@ -517,9 +890,27 @@ func Test_buf_WriteByte(t *testing.T) {
 		name    string
 		fields  fields
 		args    args
+		wantB   string
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{
+			name:   "list-full",
+			fields: fields{Byte: []byte("abc"), capt: 3, head: 0, tail: 3, mode: retain, valid: true},
+			args:   args{c: 'd'},
+			wantB:  "abc", wantErr: true,
+		},
+		{
+			name:   "ring-full",
+			fields: fields{Byte: []byte("abc"), capt: 3, head: 0, tail: 3, mode: dequeue, valid: true},
+			args:   args{c: 'd'},
+			wantB:  "bcd",
+		},
+		{
+			name:   "ring-space",
+			fields: fields{Byte: []byte("ab."), capt: 3, head: 0, tail: 2, mode: dequeue, valid: true},
+			args:   args{c: 'c'},
+			wantB:  "abc",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -534,6 +925,248 @@ func Test_buf_WriteByte(t *testing.T) {
 			if err := b.WriteByte(tt.args.c); (err != nil) != tt.wantErr {
 				t.Errorf("buf.WriteByte() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if gotB := queued(b); gotB != tt.wantB {
+				t.Errorf("buf.WriteByte() queued = %q, want %q", gotB, tt.wantB)
+			}
+		})
+	}
+}
+
+func benchmarkWriteRead(b *testing.B, w io.Writer, r io.Reader, size int) {
+	p := make([]byte, size)
+	b.SetBytes(int64(size))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.Write(p)
+		r.Read(p)
+	}
+}
+
+func BenchmarkList_WriteRead(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		capt int
+		size int
+	}{
+		{name: "pow2-64", capt: 4096, size: 64},
+		{name: "pow2-1000", capt: 4096, size: 1000},
+		{name: "odd-64", capt: 4000, size: 64},
+		{name: "odd-1000", capt: 4000, size: 1000},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			var l List
+			l.Configure(make([]byte, bm.capt))
+			benchmarkWriteRead(b, &l, &l, bm.size)
+		})
+	}
+}
+
+// bytewise is a List that copies one byte at a time and reduces positions
+// modulo capacity, as Read and Write did before copying in contiguous spans.
+// It is only the baseline for benchmarks.
+type bytewise struct {
+	Byte []byte
+	head uint32
+	tail uint32
+}
+
+func (q *bytewise) Read(p []byte) (n int, err error) {
+	capt := uint32(len(q.Byte))
+	ns, n := int(q.tail-q.head), len(p)
+	if ns <= n {
+		n, err = ns, io.EOF
+	}
+	h := q.head
+	for i := range p[:n] {
+		p[i] = q.Byte[h%capt]
+		h++
+	}
+	q.head = h
+	return
+}
+
+func (q *bytewise) Write(p []byte) (n int, err error) {
+	capt := uint32(len(q.Byte))
+	t := q.tail
+	for _, c := range p {
+		if t-q.head >= capt {
+			err = &nogc.ErrWriteOverflow
+			break
+		}
+		q.Byte[t%capt] = c
+		t++
+		n++
+	}
+	q.tail = t
+	return
+}
+
+func BenchmarkBytewise_WriteRead(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		capt int
+		size int
+	}{
+		{name: "pow2-64", capt: 4096, size: 64},
+		{name: "pow2-1000", capt: 4096, size: 1000},
+		{name: "odd-64", capt: 4000, size: 64},
+		{name: "odd-1000", capt: 4000, size: 1000},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			q := &bytewise{Byte: make([]byte, bm.capt)}
+			benchmarkWriteRead(b, q, q, bm.size)
 		})
 	}
 }
+
+func BenchmarkBytesBuffer_WriteRead(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		capt int
+		size int
+	}{
+		{name: "64", capt: 4096, size: 64},
+		{name: "1000", capt: 4096, size: 1000},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			w := bytes.NewBuffer(make([]byte, 0, bm.capt))
+			benchmarkWriteRead(b, w, w, bm.size)
+		})
+	}
+}
+
+func Test_buf_Close(t *testing.T) {
+	tests := []struct {
+		name    string
+		close   func(b *buf) error
+		wantB   string
+		wantErr error
+	}{
+		{name: "close-write", close: (*buf).CloseWrite, wantB: "ab", wantErr: io.EOF},
+		{name: "close", close: (*buf).Close, wantB: "", wantErr: io.EOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l List
+			l.Configure(make([]byte, 4))
+			l.Write([]byte("ab"))
+			if err := tt.close(&l.buf); err != nil {
+				t.Fatalf("buf.Close() error = %v", err)
+			}
+			if _, err := l.Write([]byte("c")); err != &nogc.ErrClosed {
+				t.Errorf("buf.Write() error = %v, want %v", err, &nogc.ErrClosed)
+			}
+			if err := l.WriteByte('c'); err != &nogc.ErrClosed {
+				t.Errorf("buf.WriteByte() error = %v, want %v", err, &nogc.ErrClosed)
+			}
+			if _, err := l.ReadFrom(strings.NewReader("c")); err != &nogc.ErrClosed {
+				t.Errorf("buf.ReadFrom() error = %v, want %v", err, &nogc.ErrClosed)
+			}
+			var w bytes.Buffer
+			if _, err := io.Copy(&w, &l); err != nil || w.String() != tt.wantB {
+				t.Errorf("io.Copy() = %q, %v, want %q, %v", w.String(), err, tt.wantB, nil)
+			}
+			if _, err := l.ReadByte(); err != tt.wantErr {
+				t.Errorf("buf.ReadByte() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_advance(t *testing.T) {
+	const maxPos = 2*maxCapacity - 1
+	type args struct {
+		capt uint32
+		i    uint32
+		n    uint32
+	}
+	tests := []struct {
+		name string
+		args args
+		want uint32
+	}{
+		{name: "zero", args: args{capt: 3, i: 0, n: 0}, want: 0},
+		{name: "within", args: args{capt: 3, i: 1, n: 4}, want: 5},
+		{name: "wrap", args: args{capt: 3, i: 5, n: 1}, want: 0},
+		{name: "wrap-lap", args: args{capt: 3, i: 4, n: 13}, want: 5},
+		{name: "max-wrap", args: args{capt: maxCapacity, i: maxPos, n: 1}, want: 0},
+		{name: "max-wrap-far", args: args{capt: maxCapacity, i: maxPos - 1, n: maxCapacity}, want: maxCapacity - 2},
+		{name: "max-overflow", args: args{capt: maxCapacity, i: maxPos - 2, n: math.MaxUint32}, want: maxPos - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := advance(tt.args.capt, tt.args.i, tt.args.n); got != tt.want {
+				t.Errorf("advance() = %v, want %v", got, tt.want)
+			}
+			if got := retreat(tt.args.capt, tt.want, tt.args.n); got != tt.args.i {
+				t.Errorf("retreat() = %v, want %v", got, tt.args.i)
+			}
+		})
+	}
+}
+
+func Test_distance(t *testing.T) {
+	type args struct {
+		capt uint32
+		h    uint32
+		t    uint32
+	}
+	tests := []struct {
+		name string
+		args args
+		want uint32
+	}{
+		{name: "empty", args: args{capt: 3, h: 4, t: 4}, want: 0},
+		{name: "full", args: args{capt: 3, h: 1, t: 4}, want: 3},
+		{name: "full-wrapped", args: args{capt: 3, h: 4, t: 1}, want: 3},
+		{name: "wrapped", args: args{capt: 3, h: 5, t: 0}, want: 1},
+		{name: "max-full-wrapped", args: args{capt: maxCapacity, h: 2*maxCapacity - 1, t: maxCapacity - 1}, want: maxCapacity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := distance(tt.args.capt, tt.args.h, tt.args.t); got != tt.want {
+				t.Errorf("distance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_buf_wrap(t *testing.T) {
+	// Start head and tail immediately before the positions wrap around and then
+	// push many laps of bytes through every non-power-of-2 and power-of-2 capacity
+	// checking each byte against a model queue.
+	for _, m := range []mode{retain, dequeue} {
+		for capt := uint32(1); capt <= 9; capt++ {
+			b := &buf{Byte: make([]byte, capt), capt: capt, mode: m, valid: true}
+			b.head = 2*capt - 1
+			b.tail = b.head
+			var model []byte
+			var next byte
+			for i := 0; i < 1000; i++ {
+				p := make([]byte, (i*7)%int(capt+3))
+				for j := range p {
+					p[j], next = next, next+1
+				}
+				n, _ := b.Write(p)
+				if m == retain {
+					model = append(model, p[:n]...)
+				} else if model = append(model, p...); len(model) > int(capt) {
+					model = model[len(model)-int(capt):]
+				}
+				if b.head >= 2*capt || b.tail >= 2*capt {
+					t.Fatalf("buf positions = %d, %d, want < %d", b.head, b.tail, 2*capt)
+				}
+				if got := queued(b); got != string(model) {
+					t.Fatalf("buf.Write() queued = %q, want %q", got, model)
+				}
+				q := make([]byte, (i*5)%int(capt+2))
+				n, _ = b.Read(q)
+				if string(q[:n]) != string(model[:n]) {
+					t.Fatalf("buf.Read() = %q, want %q", q[:n], model[:n])
+				}
+				model = model[n:]
+			}
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package seq
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	"github.com/ardnew/nogc"
+)
+
+// Header of a Mapped file, in order:
+//
+//	magic     4 bytes, "nogc"
+//	version   1 byte
+//	flags     1 byte, mappedDequeue if policy is Overwrite
+//	reserved  2 bytes
+//	capacity  4 bytes, little-endian
+//	reserved  4 bytes
+//	positions 8 bytes, head | tail<<32 in native byte order
+//
+// The positions are stored with a single atomic 8-byte write, so that head and
+// tail are always consistent with each other when read by another process.
+const (
+	mappedMagic      = "nogc"
+	mappedVersion    = 1
+	mappedDequeue    = 1 << 0
+	mappedPositions  = 16
+	mappedHeaderSize = 24
+)
+
+// Mapped defines a List or Ring whose backing array and positions are stored in
+// a memory-mapped file, so that its contents persist across process restarts.
+//
+// Crash consistency: every method that modifies m copies bytes into or out of
+// the mapping before publishing the new positions to the header, and publishes
+// both positions with a single atomic write. If the process crashes at any
+// point, a process that maps the file again observes a consistent state of m no
+// older than the last method to return. Bytes being read when the crash occurred
+// are read again, and bytes being written are either all present or absent.
+// When an Overwrite write must dequeue old bytes, it publishes their removal
+// before overwriting them, so a crash never exposes partially overwritten bytes
+// as old bytes.
+//
+// These guarantees hold for a process crash, since the operating system still
+// writes the mapping to the file. They hold for an operating system crash or
+// power loss only as of the last call to Sync.
+//
+// Methods of Mapped are not safe for concurrent use, and a file must not be
+// mapped by more than one Mapped at a time.
+type Mapped struct {
+	q   buf
+	mem []byte // entire mapping; header followed by backing array
+}
+
+// Map initializes m using a shared memory mapping of file f as storage.
+// The capacity of m is permanently capacity, which must be greater than 0 and no
+// greater than math.MaxInt32, and f must be open for reading and writing.
+//
+// If f is empty, it is extended to hold capacity bytes and a header, and the
+// initial length of m is 0. Otherwise, f must have been initialized by Map with
+// the same capacity and policy, and m is restored with the contents of f.
+// Returns ErrInvalidArgument if f was initialized differently or its header is
+// corrupt, or any error returned by the operating system.
+//
+// The mapping remains valid after f is closed. Call Unmap to release it. If m is
+// already mapped, its previous mapping is released first.
+func (m *Mapped) Map(f *os.File, capacity int, policy Policy) (err error) {
+	if m == nil {
+		return &nogc.ErrInvalidReceiver
+	}
+	if f == nil || capacity <= 0 || capacity > maxCapacity ||
+		(policy != Reject && policy != Overwrite) {
+		return &nogc.ErrInvalidArgument
+	}
+	if m.mem != nil {
+		if err = m.Unmap(); err != nil {
+			return err
+		}
+	}
+	var flags byte
+	if policy == Overwrite {
+		flags |= mappedDequeue
+	}
+	size := mappedHeaderSize + capacity
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	fresh := fi.Size() == 0
+	if fresh {
+		if err = f.Truncate(int64(size)); err != nil {
+			return err
+		}
+	} else if fi.Size() != int64(size) {
+		return &nogc.ErrInvalidArgument
+	}
+	mem, err := syscall.Mmap(int(f.Fd()), 0, size,
+		syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	if fresh {
+		copy(mem, mappedMagic)
+		mem[4] = mappedVersion
+		mem[5] = flags
+		binary.LittleEndian.PutUint32(mem[8:], uint32(capacity))
+	} else if string(mem[:4]) != mappedMagic || mem[4] != mappedVersion ||
+		mem[5] != flags || binary.LittleEndian.Uint32(mem[8:]) != uint32(capacity) {
+		syscall.Munmap(mem)
+		return &nogc.ErrInvalidArgument
+	}
+	m.mem = mem
+	m.q.valid = m.q.init(mem[mappedHeaderSize:], uint32(capacity), mode(policy == Overwrite))
+	pos := atomic.LoadUint64(m.positions())
+	h, t := uint32(pos), uint32(pos>>32)
+	if h >= 2*m.q.capt || t >= 2*m.q.capt || m.q.distance(h, t) > m.q.capt {
+		m.Unmap()
+		return &nogc.ErrInvalidArgument
+	}
+	m.q.head, m.q.tail = h, t
+	return nil
+}
+
+// Unmap releases the memory mapping of m. The contents of m remain in the file,
+// and m must be initialized again with Map before it is used.
+func (m *Mapped) Unmap() (err error) {
+	if m == nil || m.mem == nil {
+		return &nogc.ErrInvalidReceiver
+	}
+	err = syscall.Munmap(m.mem)
+	m.mem = nil
+	m.q = buf{}
+	return err
+}
+
+// Sync flushes the contents of m to the file, so that they persist even if the
+// operating system crashes.
+func (m *Mapped) Sync() (err error) {
+	if m == nil || !m.q.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC,
+		uintptr(unsafe.Pointer(&m.mem[0])), uintptr(len(m.mem)), syscall.MS_SYNC)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// positions returns the address of the positions in the header of m.
+func (m *Mapped) positions() *uint64 {
+	return (*uint64)(unsafe.Pointer(&m.mem[mappedPositions]))
+}
+
+// publish stores the positions of m in its header.
+func (m *Mapped) publish() {
+	atomic.StoreUint64(m.positions(), uint64(m.q.head)|uint64(m.q.tail)<<32)
+}
+
+// Len returns the number of bytes.
+func (m *Mapped) Len() int {
+	if m == nil {
+		return 0
+	}
+	return m.q.Len()
+}
+
+// Cap returns the byte capacity.
+func (m *Mapped) Cap() int {
+	if m == nil {
+		return 0
+	}
+	return m.q.Cap()
+}
+
+// Reset sets the number of bytes to 0.
+func (m *Mapped) Reset() {
+	if m == nil || !m.q.valid {
+		return
+	}
+	m.q.Reset()
+	m.publish()
+}
+
+// Read copies up to len(p) unread bytes from m to p and returns the number of
+// bytes copied, like List.Read.
+func (m *Mapped) Read(p []byte) (n int, err error) {
+	if m == nil || !m.q.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	n, err = m.q.Read(p)
+	m.publish()
+	return
+}
+
+// ReadByte returns the next unread byte from m and a nil error, like
+// List.ReadByte.
+func (m *Mapped) ReadByte() (c byte, err error) {
+	if m == nil || !m.q.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	c, err = m.q.ReadByte()
+	m.publish()
+	return
+}
+
+// UnreadByte causes the next call to ReadByte to return the last byte read,
+// like List.UnreadByte.
+func (m *Mapped) UnreadByte() (err error) {
+	if m == nil || !m.q.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	err = m.q.UnreadByte()
+	m.publish()
+	return
+}
+
+// WriteTo copies bytes from m to w until all bytes have been written or an
+// error was encountered, like List.WriteTo.
+func (m *Mapped) WriteTo(w io.Writer) (n int64, err error) {
+	if m == nil || !m.q.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	n, err = m.q.WriteTo(w)
+	m.publish()
+	return
+}
+
+// PeekAt copies up to len(p) unread bytes from m to p, starting at offset off
+// from the next unread byte, without dequeuing them, like List.PeekAt.
+func (m *Mapped) PeekAt(off int, p []byte) (n int, err error) {
+	if m == nil || !m.q.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	return m.q.PeekAt(off, p)
+}
+
+// Discard dequeues the next n unread bytes from m and returns the number of
+// bytes discarded, like List.Discard.
+func (m *Mapped) Discard(n int) (discarded int, err error) {
+	if m == nil || !m.q.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	discarded, err = m.q.Discard(n)
+	m.publish()
+	return
+}
+
+// Write appends up to len(p) bytes from p to m and returns the number of bytes
+// copied. If m is full, a Reject m writes only to the free space and returns
+// ErrWriteOverflow, and an Overwrite m dequeues the oldest bytes to make room,
+// like List.Write and Ring.Write, respectively.
+func (m *Mapped) Write(p []byte) (n int, err error) {
+	if m == nil || !m.q.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	if m.q.mode == dequeue {
+		// Only the last capt bytes of p can be retained in m.
+		if len(p) > int(m.q.capt) {
+			n = len(p) - int(m.q.capt)
+			p = p[n:]
+		}
+		m.evict(uint32(len(p)))
+	}
+	nw, err := m.q.Write(p)
+	m.publish()
+	return n + nw, err
+}
+
+// WriteByte appends c to m and returns nil. If m is full, a Reject m returns
+// ErrWriteOverflow, and an Overwrite m dequeues the oldest byte to make room,
+// like List.WriteByte and Ring.WriteByte, respectively.
+func (m *Mapped) WriteByte(c byte) (err error) {
+	if m == nil || !m.q.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	if m.q.mode == dequeue {
+		m.evict(1)
+	}
+	err = m.q.WriteByte(c)
+	m.publish()
+	return
+}
+
+// ReadFrom copies bytes from r to m until all bytes have been read or an error
+// was encountered, and returns the number of bytes successfully copied. If m is
+// full, a Reject m returns ErrReadOverflow, and an Overwrite m dequeues the
+// oldest bytes to make room, like List.ReadFrom and Ring.ReadFrom, respectively.
+// An Overwrite m publishes the removal of the oldest bytes before each read from
+// r, so the bytes observed after a crash are never those r used as scratch space.
+func (m *Mapped) ReadFrom(r io.Reader) (n int64, err error) {
+	if m == nil || !m.q.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if r == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	if m.q.mode == retain {
+		// A List only reads into free space, so no bytes are overwritten.
+		n, err = m.q.ReadFrom(r)
+		m.publish()
+		return
+	}
+	if wt, ok := r.(io.WriterTo); ok {
+		// Let r copy from its own storage with Write, which evicts only the bytes
+		// it is about to replace.
+		return wt.WriteTo(m)
+	}
+	b := &m.q
+	// step is the number of the oldest bytes evicted for the next read once m is
+	// full, like Ring.ReadFrom.
+	step := uint32(1)
+	for {
+		// Read into the free space up to the end of the backing array, or, if
+		// there is none, into the oldest bytes up to the end of the backing array,
+		// whose removal is published first. Since r may use all of the space it is
+		// given as scratch space, evicted bytes stay evicted even if r returns
+		// fewer bytes than requested.
+		it := b.index(b.tail)
+		nr := b.capt - it
+		nf := b.capt - b.distance(b.head, b.tail)
+		if nf > 0 && nf < nr {
+			nr = nf
+		} else if nf == 0 && step < nr {
+			nr = step
+		}
+		m.evict(nr)
+		nc, errr := r.Read(b.Byte[it : it+nr])
+		n += int64(nc)
+		if nf == 0 {
+			step = nextStep(nr, uint32(nc))
+		}
+		b.tail = b.advance(b.tail, uint32(nc))
+		b.enqueued(uint32(nc))
+		m.publish()
+		if errr != nil {
+			// Catch any attempt to return io.EOF and return nil instead.
+			// See documentation on io.ReaderFrom, and io.Copy.
+			if errr == io.EOF {
+				errr = nil
+			}
+			return n, errr
+		}
+	}
+}
+
+// evict dequeues and publishes the removal of the oldest bytes in m until there
+// are at least n bytes of free space, before those bytes are overwritten, and
+// returns the number of bytes dequeued.
+func (m *Mapped) evict(n uint32) (evicted uint32) {
+	if nf := m.q.capt - m.q.distance(m.q.head, m.q.tail); n > nf {
+		evicted = n - nf
+		m.q.head = m.q.advance(m.q.head, evicted)
+		m.publish()
+	}
+	return
+}
@@ -0,0 +1,209 @@
+package seq
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ardnew/nogc"
+)
+
+var _ nogc.Buffer = (*Mapped)(nil)
+
+// mapped returns a Mapped over the file at path, failing the test on error.
+func mapped(t *testing.T, path string, capacity int, policy Policy) *Mapped {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var m Mapped
+	if err := m.Map(f, capacity, policy); err != nil {
+		t.Fatalf("Mapped.Map() error = %v", err)
+	}
+	t.Cleanup(func() { m.Unmap() })
+	return &m
+}
+
+func TestMapped_Map(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list")
+	m := mapped(t, path, 5, Reject)
+	m.Write([]byte("abcd"))
+	m.Read(make([]byte, 2))
+	if n, err := m.Write([]byte("efgh")); n != 3 || err != &nogc.ErrWriteOverflow {
+		t.Errorf("Mapped.Write() = %v, %v, want %v, %v", n, err, 3, &nogc.ErrWriteOverflow)
+	}
+	// Mapping the file again without unmapping it first observes the same state
+	// as a process restarted after a crash.
+	r := mapped(t, path, 5, Reject)
+	p := make([]byte, 8)
+	if n, _ := r.PeekAt(0, p); string(p[:n]) != "cdefg" {
+		t.Errorf("Mapped.Map() contents = %q, want %q", p[:n], "cdefg")
+	}
+	if err := m.Sync(); err != nil {
+		t.Errorf("Mapped.Sync() error = %v", err)
+	}
+	m.Unmap()
+	r.Discard(1)
+	r.WriteByte('h')
+	m = mapped(t, path, 5, Reject)
+	if n, _ := m.Read(p); string(p[:n]) != "defgh" {
+		t.Errorf("Mapped.Map() contents = %q, want %q", p[:n], "defgh")
+	}
+}
+
+func TestMapped_Map_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	m := mapped(t, path, 5, Overwrite)
+	m.Write([]byte("abc"))
+	m.Unmap()
+	tests := []struct {
+		name     string
+		capacity int
+		policy   Policy
+		corrupt  func(p []byte)
+	}{
+		{name: "capacity", capacity: 6, policy: Overwrite},
+		{name: "policy", capacity: 5, policy: Reject},
+		{name: "magic", capacity: 5, policy: Overwrite, corrupt: func(p []byte) { p[0] = 'N' }},
+		{name: "positions", capacity: 5, policy: Overwrite, corrupt: func(p []byte) { p[mappedPositions] = 10 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := os.ReadFile(path)
+			if tt.corrupt != nil {
+				tt.corrupt(data)
+			}
+			bad := filepath.Join(t.TempDir(), "bad")
+			os.WriteFile(bad, data, 0o600)
+			f, err := os.OpenFile(bad, os.O_RDWR, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			var m Mapped
+			if err := m.Map(f, tt.capacity, tt.policy); err != &nogc.ErrInvalidArgument {
+				t.Errorf("Mapped.Map() error = %v, want %v", err, &nogc.ErrInvalidArgument)
+			}
+		})
+	}
+}
+
+func TestMapped_Write(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	m := mapped(t, path, 5, Overwrite)
+	r := mapped(t, path, 5, Overwrite)
+	var want string
+	for i, s := range []string{"abc", "def", "g", "hijklmn", "op"} {
+		if n, err := m.Write([]byte(s)); n != len(s) || err != nil {
+			t.Fatalf("Mapped.Write() = %v, %v, want %v, %v", n, err, len(s), nil)
+		}
+		if want += s; len(want) > 5 {
+			want = want[len(want)-5:]
+		}
+		// Reopen the file after every write, as after a crash.
+		r.Unmap()
+		r = mapped(t, path, 5, Overwrite)
+		p := make([]byte, 8)
+		if n, _ := r.PeekAt(0, p); string(p[:n]) != want {
+			t.Errorf("Mapped.Write() %d contents = %q, want %q", i, p[:n], want)
+		}
+	}
+	m.Reset()
+	if r.Unmap(); mapped(t, path, 5, Overwrite).Len() != 0 {
+		t.Errorf("Mapped.Reset() Len() != 0")
+	}
+}
+
+func TestMapped_Map_Again(t *testing.T) {
+	dir := t.TempDir()
+	m := mapped(t, filepath.Join(dir, "list"), 5, Reject)
+	m.Write([]byte("abc"))
+	f, err := os.OpenFile(filepath.Join(dir, "ring"), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	// The previous mapping is released before m is initialized again.
+	if err := m.Map(f, 3, Overwrite); err != nil {
+		t.Fatalf("Mapped.Map() error = %v", err)
+	}
+	m.Write([]byte("wxyz"))
+	if m.Len() != 3 || m.Cap() != 3 {
+		t.Errorf("Mapped.Map() Len, Cap = %v, %v, want %v, %v", m.Len(), m.Cap(), 3, 3)
+	}
+	p := make([]byte, 8)
+	if n, _ := mapped(t, filepath.Join(dir, "list"), 5, Reject).Read(p); string(p[:n]) != "abc" {
+		t.Errorf("Mapped.Map() previous contents = %q, want %q", p[:n], "abc")
+	}
+}
+
+func TestMapped_ReadFrom(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		scratch bool
+		src     []string
+		wantN   []int64
+		wantErr error
+		want    string
+	}{
+		{name: "list", policy: Reject, src: []string{"abc", "defg"}, wantN: []int64{3, 2}, want: "abcde"},
+		{name: "list-full", policy: Reject, src: []string{"abcde", "f"}, wantN: []int64{5, 0}, wantErr: &nogc.ErrReadOverflow, want: "abcde"},
+		{name: "ring", policy: Overwrite, src: []string{"abc", "defg"}, wantN: []int64{3, 4}, want: "cdefg"},
+		{name: "ring-long", policy: Overwrite, src: []string{"abcd", "efghijklm"}, wantN: []int64{4, 9}, want: "ijklm"},
+		{name: "ring-empty", policy: Overwrite, src: []string{"abcdefg", ""}, wantN: []int64{7, 0}, want: "cdefg"},
+		{name: "list-scratch", policy: Reject, scratch: true, src: []string{"abc", "X"}, wantN: []int64{3, 1}, want: "abcX"},
+		{name: "ring-scratch", policy: Overwrite, scratch: true, src: []string{"abc", "X"}, wantN: []int64{3, 1}, want: "abcX"},
+		{name: "ring-full-scratch", policy: Overwrite, scratch: true, src: []string{"abcde", "XY"}, wantN: []int64{5, 2}, want: "eXY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.name)
+			m := mapped(t, path, 5, tt.policy)
+			var err error
+			for i, src := range tt.src {
+				var r io.Reader = strings.NewReader(src)
+				if tt.scratch {
+					r = &scratchReader{s: src}
+				}
+				var n int64
+				if n, err = m.ReadFrom(r); n != tt.wantN[i] {
+					t.Errorf("Mapped.ReadFrom() = %v, want %v", n, tt.wantN[i])
+				}
+			}
+			if err != tt.wantErr {
+				t.Errorf("Mapped.ReadFrom() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			// Reopen the file, as after a crash.
+			m.Unmap()
+			m = mapped(t, path, 5, tt.policy)
+			var w bytes.Buffer
+			if m.WriteTo(&w); w.String() != tt.want {
+				t.Errorf("Mapped.ReadFrom() contents = %q, want %q", w.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestMapped_UnreadByte(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list")
+	m := mapped(t, path, 5, Reject)
+	m.Write([]byte("abc"))
+	m.ReadByte()
+	if err := m.UnreadByte(); err != nil {
+		t.Errorf("Mapped.UnreadByte() error = %v", err)
+	}
+	var w bytes.Buffer
+	if n, err := m.WriteTo(&w); n != 3 || err != nil {
+		t.Errorf("Mapped.WriteTo() = %v, %v, want %v, %v", n, err, 3, nil)
+	}
+	r := mapped(t, path, 5, Reject)
+	if r.Len() != 0 || w.String() != "abc" {
+		t.Errorf("Mapped.WriteTo() = %q, Len() %v, want %q, %v", w.String(), r.Len(), "abc", 0)
+	}
+}
@@ -0,0 +1,305 @@
+package seq
+
+import (
+	"io"
+	"math"
+	"sync/atomic"
+
+	"github.com/ardnew/nogc"
+)
+
+// Slot defines an element of storage for MPMC. Each Slot pairs an element with
+// the sequence number that grants exclusive access to it, so that producers and
+// consumers need only contend on the queue indices and never on a lock.
+type Slot[T any] struct {
+	seq uint32
+	val T
+}
+
+// MPMC defines a fixed-length queue of elements of any type T in which no
+// elements may be added when the queue is full, like List, that is safe for
+// concurrent use by any number of producer and consumer goroutines.
+//
+// Push and Pop never block. A goroutine racing others for the same slot simply
+// retries with the next one, so all goroutines make progress as long as any of
+// them is scheduled. Configure and Reset must not be called concurrently with
+// any other method.
+type MPMC[T any] struct {
+	Slot  []Slot[T]
+	mask  uint32
+	valid bool
+	_     [cacheLineSize]byte
+	enq   uint32 // position of next Push
+	_     [cacheLineSize - 4]byte
+	deq   uint32 // position of next Pop
+	_     [cacheLineSize - 4]byte
+}
+
+// Configure initializes q using all of p as storage.
+// The initial length of q is 0; any data already in p may be overwritten.
+// The capacity of q is permanently len(p), which must be a power of 2 greater
+// than 1.
+// Callers must not modify p after initializing.
+func (q *MPMC[T]) Configure(p []Slot[T]) (ok bool) {
+	if q == nil {
+		return false
+	}
+	n := len(p)
+	q.valid = n > 1 && n&(n-1) == 0 && n <= math.MaxInt32
+	if !q.valid {
+		return false
+	}
+	q.Slot = p
+	q.mask = uint32(n - 1)
+	q.Reset()
+	return true
+}
+
+// Len returns the number of elements.
+//
+// If called concurrently with any producer or consumer, the result is only a
+// snapshot that may be stale by the time it is returned.
+func (q *MPMC[T]) Len() int {
+	if q == nil || !q.valid {
+		return 0
+	}
+	d := atomic.LoadUint32(&q.deq)
+	e := atomic.LoadUint32(&q.enq)
+	if n := int32(e - d); n > 0 {
+		if n > int32(q.mask+1) {
+			return int(q.mask + 1)
+		}
+		return int(n)
+	}
+	return 0
+}
+
+// Cap returns the element capacity.
+func (q *MPMC[T]) Cap() int {
+	if q == nil || !q.valid {
+		return 0
+	}
+	return int(q.mask + 1)
+}
+
+// Reset sets the number of elements to 0.
+func (q *MPMC[T]) Reset() {
+	if q == nil || !q.valid {
+		return
+	}
+	var zero T
+	for i := range q.Slot {
+		q.Slot[i].val = zero
+		atomic.StoreUint32(&q.Slot[i].seq, uint32(i))
+	}
+	atomic.StoreUint32(&q.enq, 0)
+	atomic.StoreUint32(&q.deq, 0)
+}
+
+// Push appends v to q and returns nil.
+// If q is full, returns ErrWriteOverflow.
+func (q *MPMC[T]) Push(v T) (err error) {
+	if q == nil || !q.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	s, pos, ok := q.claimPush()
+	if !ok {
+		return &nogc.ErrWriteOverflow
+	}
+	s.val = v
+	q.publishPush(s, pos)
+	return nil
+}
+
+// Pop removes and returns the oldest element from q and a nil error.
+// If q is empty, returns the zero value of T and ErrEmpty.
+func (q *MPMC[T]) Pop() (v T, err error) {
+	if q == nil || !q.valid {
+		return v, &nogc.ErrInvalidReceiver
+	}
+	s, pos, ok := q.claimPop()
+	if !ok {
+		return v, &nogc.ErrEmpty
+	}
+	var zero T
+	v, s.val = s.val, zero
+	q.publishPop(s, pos)
+	return v, nil
+}
+
+// claimPush reserves the slot at the next Push position for the caller, who
+// then has exclusive access to it until calling publishPush.
+// Returns ok == false if q is full.
+func (q *MPMC[T]) claimPush() (s *Slot[T], pos uint32, ok bool) {
+	pos = atomic.LoadUint32(&q.enq)
+	for {
+		s = &q.Slot[pos&q.mask]
+		// A slot is ready for Push at position pos when its sequence number equals
+		// pos. It lags behind pos when the slot still holds the element pushed one
+		// lap earlier (q is full), and it leads pos when another producer claimed
+		// the slot since we loaded pos.
+		switch dif := int32(atomic.LoadUint32(&s.seq) - pos); {
+		case dif == 0:
+			if atomic.CompareAndSwapUint32(&q.enq, pos, pos+1) {
+				return s, pos, true
+			}
+			pos = atomic.LoadUint32(&q.enq)
+		case dif < 0:
+			return nil, pos, false
+		default:
+			pos = atomic.LoadUint32(&q.enq)
+		}
+	}
+}
+
+// publishPush hands the slot claimed at position pos to consumers.
+func (q *MPMC[T]) publishPush(s *Slot[T], pos uint32) {
+	atomic.StoreUint32(&s.seq, pos+1)
+}
+
+// claimPop reserves the slot at the next Pop position for the caller, who then
+// has exclusive access to it until calling publishPop.
+// Returns ok == false if q is empty.
+func (q *MPMC[T]) claimPop() (s *Slot[T], pos uint32, ok bool) {
+	pos = atomic.LoadUint32(&q.deq)
+	for {
+		s = &q.Slot[pos&q.mask]
+		// A slot is ready for Pop at position pos when its sequence number equals
+		// pos+1, as published by publishPush. It lags behind when no element has
+		// been pushed yet (q is empty), and it leads when another consumer claimed
+		// the slot since we loaded pos.
+		switch dif := int32(atomic.LoadUint32(&s.seq) - (pos + 1)); {
+		case dif == 0:
+			if atomic.CompareAndSwapUint32(&q.deq, pos, pos+1) {
+				return s, pos, true
+			}
+			pos = atomic.LoadUint32(&q.deq)
+		case dif < 0:
+			return nil, pos, false
+		default:
+			pos = atomic.LoadUint32(&q.deq)
+		}
+	}
+}
+
+// publishPop hands the slot claimed at position pos back to producers for use
+// in the next lap around the queue.
+func (q *MPMC[T]) publishPop(s *Slot[T], pos uint32) {
+	atomic.StoreUint32(&s.seq, pos+q.mask+1)
+}
+
+// MPMCRecord defines a fixed-length queue of variable-length byte records in
+// which no records may be added when the queue is full, that is safe for
+// concurrent use by any number of producer and consumer goroutines.
+//
+// Each record is stored in a fixed-size region of the backing array, so that
+// the bytes of a record are copied directly between the caller and Byte with no
+// intermediate buffering.
+type MPMCRecord struct {
+	Byte []byte
+	size uint32
+	q    MPMC[uint32] // slot value is record length
+}
+
+// Configure initializes r using all of slots and p as storage.
+// The initial length of r is 0; any data already in p may be overwritten.
+// The record capacity of r is permanently len(slots), which must be a power of
+// 2 greater than 1, and p is divided evenly into regions of len(p)/len(slots)
+// bytes, which is the maximum length of each record.
+// Callers must not modify slots or p after initializing.
+func (r *MPMCRecord) Configure(slots []Slot[uint32], p []byte) (ok bool) {
+	if r == nil {
+		return false
+	}
+	if !r.q.Configure(slots) || len(p) < len(slots) {
+		r.q.valid = false
+		return false
+	}
+	r.Byte = p
+	r.size = uint32(len(p) / len(slots))
+	return true
+}
+
+// Len returns the number of records.
+func (r *MPMCRecord) Len() int {
+	if r == nil {
+		return 0
+	}
+	return r.q.Len()
+}
+
+// Cap returns the record capacity.
+func (r *MPMCRecord) Cap() int {
+	if r == nil {
+		return 0
+	}
+	return r.q.Cap()
+}
+
+// Size returns the maximum length of each record.
+func (r *MPMCRecord) Size() int {
+	if r == nil || !r.q.valid {
+		return 0
+	}
+	return int(r.size)
+}
+
+// Reset sets the number of records to 0.
+func (r *MPMCRecord) Reset() {
+	if r == nil {
+		return
+	}
+	r.q.Reset()
+}
+
+// Write appends all of p to r as a single record and returns len(p).
+//
+// Records are never partially written. If len(p) exceeds Size, returns
+// ErrOutOfRange, and if r is full, returns ErrWriteOverflow.
+func (r *MPMCRecord) Write(p []byte) (n int, err error) {
+	if r == nil || !r.q.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	if uint32(len(p)) > r.size {
+		return 0, &nogc.ErrOutOfRange
+	}
+	s, pos, ok := r.q.claimPush()
+	if !ok {
+		return 0, &nogc.ErrWriteOverflow
+	}
+	lo := (pos & r.q.mask) * r.size
+	n = copy(r.Byte[lo:lo+r.size], p)
+	s.val = uint32(n)
+	r.q.publishPush(s, pos)
+	return n, nil
+}
+
+// Read removes the oldest record from r, copies it to p, and returns the number
+// of bytes copied, which is the length of the record if p can hold it.
+// If r is empty, returns 0, ErrEmpty.
+//
+// The record is removed even if p is too small to hold it, in which case only
+// the first len(p) bytes are copied and Read returns len(p), io.ErrShortBuffer.
+// Callers can avoid this by providing p with length of at least Size.
+func (r *MPMCRecord) Read(p []byte) (n int, err error) {
+	if r == nil || !r.q.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	s, pos, ok := r.q.claimPop()
+	if !ok {
+		return 0, &nogc.ErrEmpty
+	}
+	lo := (pos & r.q.mask) * r.size
+	n = copy(p, r.Byte[lo:lo+s.val])
+	if n < int(s.val) {
+		err = io.ErrShortBuffer
+	}
+	r.q.publishPop(s, pos)
+	return n, err
+}
@@ -0,0 +1,178 @@
+package seq
+
+import (
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ardnew/nogc"
+)
+
+func TestMPMC_Configure(t *testing.T) {
+	tests := []struct {
+		name   string
+		n      int
+		wantOk bool
+	}{
+		{name: "nil", n: 0, wantOk: false},
+		{name: "one", n: 1, wantOk: false},
+		{name: "two", n: 2, wantOk: true},
+		{name: "not-power-of-2", n: 12, wantOk: false},
+		{name: "power-of-2", n: 16, wantOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var q MPMC[int]
+			if gotOk := q.Configure(make([]Slot[int], tt.n)); gotOk != tt.wantOk {
+				t.Errorf("MPMC.Configure() = %v, want %v", gotOk, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestMPMC_Push(t *testing.T) {
+	var q MPMC[int]
+	q.Configure(make([]Slot[int], 4))
+	for lap := 0; lap < 3; lap++ {
+		for i := 0; i < 4; i++ {
+			if err := q.Push(lap*4 + i); err != nil {
+				t.Fatalf("MPMC.Push() error = %v", err)
+			}
+		}
+		if err := q.Push(-1); err == nil {
+			t.Errorf("MPMC.Push() error = %v, wantErr %v", err, true)
+		}
+		if q.Len() != 4 {
+			t.Errorf("MPMC.Len() = %v, want %v", q.Len(), 4)
+		}
+		for i := 0; i < 4; i++ {
+			if v, err := q.Pop(); err != nil || v != lap*4+i {
+				t.Errorf("MPMC.Pop() = %v, %v, want %v, %v", v, err, lap*4+i, nil)
+			}
+		}
+		if _, err := q.Pop(); err != &nogc.ErrEmpty {
+			t.Errorf("MPMC.Pop() error = %v, want %v", err, &nogc.ErrEmpty)
+		}
+	}
+}
+
+func TestMPMC_Concurrent(t *testing.T) {
+	const (
+		producers = 4
+		consumers = 4
+		each      = 1 << 12
+	)
+	var q MPMC[int]
+	q.Configure(make([]Slot[int], 64))
+	var seen [producers * each]int32
+	var popped int32
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < each; {
+				if q.Push(p*each+i) == nil {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt32(&popped) < producers*each {
+				if v, err := q.Pop(); err == nil {
+					atomic.AddInt32(&seen[v], 1)
+					atomic.AddInt32(&popped, 1)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for v, n := range seen {
+		if n != 1 {
+			t.Fatalf("MPMC.Pop() returned %d %d times, want 1", v, n)
+		}
+	}
+}
+
+func TestMPMCRecord_Write(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       []byte
+		wantN   int
+		wantErr bool
+	}{
+		{name: "empty", p: []byte{}, wantN: 0},
+		{name: "short", p: []byte("ab"), wantN: 2},
+		{name: "exact", p: []byte("abcd"), wantN: 4},
+		{name: "too-long", p: []byte("abcde"), wantN: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r MPMCRecord
+			r.Configure(make([]Slot[uint32], 2), make([]byte, 8))
+			gotN, err := r.Write(tt.p)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MPMCRecord.Write() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotN != tt.wantN {
+				t.Errorf("MPMCRecord.Write() = %v, want %v", gotN, tt.wantN)
+			}
+			if tt.wantErr {
+				return
+			}
+			p := make([]byte, r.Size())
+			n, err := r.Read(p)
+			if err != nil || string(p[:n]) != string(tt.p) {
+				t.Errorf("MPMCRecord.Read() = %q, %v, want %q, %v", p[:n], err, tt.p, nil)
+			}
+		})
+	}
+}
+
+func TestMPMCRecord_Read(t *testing.T) {
+	var r MPMCRecord
+	r.Configure(make([]Slot[uint32], 2), make([]byte, 8))
+	r.Write([]byte("abc"))
+	r.Write([]byte("d"))
+	if _, err := r.Write([]byte("e")); err == nil {
+		t.Errorf("MPMCRecord.Write() error = %v, wantErr %v", err, true)
+	}
+	p := make([]byte, 2)
+	if n, err := r.Read(p); n != 2 || err != io.ErrShortBuffer {
+		t.Errorf("MPMCRecord.Read() = %v, %v, want %v, %v", n, err, 2, io.ErrShortBuffer)
+	}
+	if n, err := r.Read(p); n != 1 || err != nil || p[0] != 'd' {
+		t.Errorf("MPMCRecord.Read() = %q, %v, want %q, %v", p[:n], err, "d", nil)
+	}
+	if _, err := r.Read(p); err != &nogc.ErrEmpty {
+		t.Errorf("MPMCRecord.Read() error = %v, want %v", err, &nogc.ErrEmpty)
+	}
+}
+
+func TestMPMC_Allocs(t *testing.T) {
+	var q MPMC[sample]
+	q.Configure(make([]Slot[sample], 8))
+	var r MPMCRecord
+	r.Configure(make([]Slot[uint32], 4), make([]byte, 64))
+	p := make([]byte, 16)
+	allocs := testing.AllocsPerRun(100, func() {
+		q.Push(sample{id: 1})
+		q.Pop()
+		r.Write(p)
+		r.Read(p)
+	})
+	if allocs != 0 {
+		t.Errorf("MPMC allocs = %v, want 0", allocs)
+	}
+}
@@ -0,0 +1,94 @@
+package seq
+
+import (
+	"github.com/ardnew/nogc"
+)
+
+// peekSpans returns the one or two contiguous regions of the backing array that
+// together form the n elements starting at offset off from the first-in (head)
+// element. The caller is responsible for ensuring off+n <= Len.
+func (b *buf) peekSpans(off, n uint32) (r1, r2 []byte) {
+	return spans(b.Byte, b.capt, b.index(b.advance(b.head, off)), n)
+}
+
+// Peek copies up to len(p) unread bytes from b to p without dequeuing them and
+// returns the number of bytes copied.
+//
+// Peek is equivalent to PeekAt(0, p).
+func (b *buf) Peek(p []byte) (n int, err error) {
+	return b.PeekAt(0, p)
+}
+
+// PeekAt copies up to len(p) unread bytes from b to p, starting at offset off
+// from the next unread byte, without dequeuing them and returns the number of
+// bytes copied.
+//
+// Like io.ReaderAt, if PeekAt returns n < len(p), it also returns a non-nil
+// error; if there are fewer than off+len(p) bytes in b, returns ErrEmpty, or
+// io.EOF if b is closed.
+func (b *buf) PeekAt(off int, p []byte) (n int, err error) {
+	if b == nil || !b.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	ns := b.Len()
+	if off < 0 || off > ns {
+		return 0, &nogc.ErrOutOfRange
+	}
+	if ns -= off; len(p) < ns {
+		ns = len(p)
+	}
+	r1, r2 := b.peekSpans(uint32(off), uint32(ns))
+	n = copy(p, r1)
+	n += copy(p[n:], r2)
+	if n < len(p) {
+		err = b.empty()
+	}
+	return
+}
+
+// Discard dequeues the next n unread bytes from b and returns the number of
+// bytes discarded.
+//
+// If Discard dequeues fewer than n bytes, it also returns ErrEmpty, or io.EOF
+// if b is closed.
+func (b *buf) Discard(n int) (discarded int, err error) {
+	if b == nil || !b.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if n < 0 {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	discarded = b.Len()
+	if n < discarded {
+		discarded = n
+	} else if n > discarded {
+		err = b.empty()
+	}
+	b.head = b.advance(b.head, uint32(discarded))
+	b.dequeued(uint32(discarded))
+	return
+}
+
+// Next returns a slice containing up to the next n unread bytes from b,
+// dequeuing them as if they had been returned by Read.
+//
+// The returned slice is a view into the backing array, so it is only valid until
+// the next call to a method that writes to b. Since the view must be contiguous,
+// the slice is shorter than n if there are fewer than n bytes in b or if the
+// bytes wrap around the end of the backing array. In the latter case, the
+// remaining bytes are returned by the next call to Next.
+func (b *buf) Next(n int) []byte {
+	if b == nil || !b.valid || n <= 0 {
+		return nil
+	}
+	if ns := b.Len(); n > ns {
+		n = ns
+	}
+	r1, _ := b.peekSpans(0, uint32(n))
+	b.head = b.advance(b.head, uint32(len(r1)))
+	b.dequeued(uint32(len(r1)))
+	return r1
+}
@@ -0,0 +1,130 @@
+package seq
+
+import (
+	"io"
+	"testing"
+
+	"github.com/ardnew/nogc"
+)
+
+func Test_buf_PeekAt(t *testing.T) {
+	type fields struct {
+		Byte []byte
+		capt uint32
+		head uint32
+		tail uint32
+		shut bool
+	}
+	type args struct {
+		off int
+		p   []byte
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantN   int
+		wantP   string
+		wantErr error
+	}{
+		{
+			name:   "contiguous",
+			fields: fields{Byte: []byte("abcdef"), capt: 6, head: 0, tail: 6},
+			args:   args{off: 1, p: make([]byte, 3)},
+			wantN:  3, wantP: "bcd",
+		},
+		{
+			name:   "wrapped",
+			fields: fields{Byte: []byte("efabcd"), capt: 6, head: 2, tail: 8},
+			args:   args{off: 2, p: make([]byte, 3)},
+			wantN:  3, wantP: "cde",
+		},
+		{
+			name:   "short",
+			fields: fields{Byte: []byte("efabcd"), capt: 6, head: 2, tail: 8},
+			args:   args{off: 4, p: make([]byte, 3)},
+			wantN:  2, wantP: "ef", wantErr: &nogc.ErrEmpty,
+		},
+		{
+			name:   "end",
+			fields: fields{Byte: []byte("abc"), capt: 3, head: 0, tail: 3},
+			args:   args{off: 3, p: make([]byte, 1)},
+			wantN:  0, wantP: "", wantErr: &nogc.ErrEmpty,
+		},
+		{
+			name:   "end-closed",
+			fields: fields{Byte: []byte("abc"), capt: 3, head: 0, tail: 3, shut: true},
+			args:   args{off: 3, p: make([]byte, 1)},
+			wantN:  0, wantP: "", wantErr: io.EOF,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &buf{
+				Byte:  tt.fields.Byte,
+				capt:  tt.fields.capt,
+				head:  tt.fields.head,
+				tail:  tt.fields.tail,
+				shut:  tt.fields.shut,
+				valid: true,
+			}
+			want := queued(b)
+			gotN, err := b.PeekAt(tt.args.off, tt.args.p)
+			if err != tt.wantErr {
+				t.Errorf("buf.PeekAt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotN != tt.wantN {
+				t.Errorf("buf.PeekAt() = %v, want %v", gotN, tt.wantN)
+			}
+			if gotP := string(tt.args.p[:gotN]); gotP != tt.wantP {
+				t.Errorf("buf.PeekAt() = %q, want %q", gotP, tt.wantP)
+			}
+			if got := queued(b); got != want {
+				t.Errorf("buf.PeekAt() queued = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func Test_buf_Discard(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		wantD   int
+		wantB   string
+		wantErr bool
+	}{
+		{name: "none", n: 0, wantD: 0, wantB: "abcde"},
+		{name: "some", n: 3, wantD: 3, wantB: "de"},
+		{name: "all", n: 5, wantD: 5, wantB: ""},
+		{name: "more", n: 6, wantD: 5, wantB: "", wantErr: true},
+		{name: "negative", n: -1, wantD: 0, wantB: "abcde", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &buf{Byte: []byte("cdeab"), capt: 5, head: 3, tail: 8, valid: true}
+			gotD, err := b.Discard(tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("buf.Discard() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotD != tt.wantD {
+				t.Errorf("buf.Discard() = %v, want %v", gotD, tt.wantD)
+			}
+			if got := queued(b); got != tt.wantB {
+				t.Errorf("buf.Discard() queued = %q, want %q", got, tt.wantB)
+			}
+		})
+	}
+}
+
+func Test_buf_Next(t *testing.T) {
+	b := &buf{Byte: []byte("bcdea"), capt: 5, head: 4, tail: 9, valid: true}
+	for _, want := range []string{"a", "bc", "de", ""} {
+		if got := string(b.Next(2)); got != want {
+			t.Errorf("buf.Next() = %q, want %q", got, want)
+		}
+	}
+	if b.Len() != 0 {
+		t.Errorf("buf.Len() = %v, want %v", b.Len(), 0)
+	}
+}
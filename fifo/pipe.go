@@ -0,0 +1,396 @@
+package seq
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ardnew/nogc"
+)
+
+// Pipe defines a synchronous in-memory pipe, like io.Pipe, in which bytes are
+// buffered in a List so that a writer only blocks while the List is full and a
+// reader only blocks while the List is empty.
+//
+// Reads and writes on the pipe are safe for concurrent use by any number of
+// goroutines. Parallel calls to Read, and parallel calls to Write, are also safe:
+// the individual calls will be gated sequentially.
+//
+// Blocked reads and writes may also be abandoned by cancelling a context or by
+// setting a deadline, neither of which create any timer or goroutine per call.
+//
+// Configure allocates the channels used to signal blocked readers and writers,
+// but only the first time it is called. Setting a deadline allocates a timer,
+// but only when no timer exists or the previous deadline has expired. No other
+// method allocates.
+type Pipe struct {
+	mu    sync.Mutex // guards list, rerr, and werr
+	list  List
+	wsem  chan struct{} // held by Write so that bytes are not interleaved
+	rdy   chan struct{} // signaled when bytes were written or the pipe closed
+	spc   chan struct{} // signaled when bytes were read or the pipe closed
+	rerr  error         // set when the reader is closed
+	werr  error         // set when the writer is closed
+	rdl   deadline
+	wdl   deadline
+	valid bool
+}
+
+// PipeReader is the read half of a Pipe.
+type PipeReader struct{ p *Pipe }
+
+// PipeWriter is the write half of a Pipe.
+type PipeWriter struct{ p *Pipe }
+
+// Configure initializes p using all of b as storage and opens both halves of
+// the pipe.
+// The initial length of p is 0; any data already in b may be overwritten.
+// The capacity of p is permanently len(b).
+// Callers must not modify b after initializing, and must not call Configure
+// concurrently with any other method.
+func (p *Pipe) Configure(b []byte) (ok bool) {
+	if p == nil {
+		return false
+	}
+	if p.rdy == nil {
+		p.wsem = make(chan struct{}, 1)
+		p.rdy = make(chan struct{}, 1)
+		p.spc = make(chan struct{}, 1)
+	}
+	p.rerr, p.werr = nil, nil
+	p.rdl.set(time.Time{})
+	p.wdl.set(time.Time{})
+	p.valid = p.list.Configure(b) && len(b) > 0
+	return p.valid
+}
+
+// Reader returns the read half of p.
+func (p *Pipe) Reader() PipeReader { return PipeReader{p} }
+
+// Writer returns the write half of p.
+func (p *Pipe) Writer() PipeWriter { return PipeWriter{p} }
+
+// Len returns the number of buffered bytes.
+func (p *Pipe) Len() int {
+	if p == nil || !p.valid {
+		return 0
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.list.Len()
+}
+
+// Cap returns the byte capacity.
+func (p *Pipe) Cap() int {
+	if p == nil || !p.valid {
+		return 0
+	}
+	return p.list.Cap()
+}
+
+// signal wakes one goroutine waiting on c, or the next goroutine to wait on c
+// if none are waiting. Redundant signals are coalesced.
+func signal(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Pipe) read(ctx context.Context, b []byte) (n int, err error) {
+	for {
+		if err = p.rdl.check(ctx); err != nil {
+			return 0, err
+		}
+		p.mu.Lock()
+		if p.rerr != nil {
+			p.mu.Unlock()
+			return 0, io.ErrClosedPipe
+		}
+		if p.list.Len() > 0 {
+			n, _ = p.list.Read(b)
+			more := p.list.Len() > 0
+			p.mu.Unlock()
+			signal(p.spc)
+			if more {
+				// Pass the signal along to any other blocked reader.
+				signal(p.rdy)
+			}
+			return n, nil
+		}
+		if p.werr != nil {
+			p.mu.Unlock()
+			signal(p.rdy)
+			return 0, p.werr
+		}
+		p.mu.Unlock()
+		if len(b) == 0 {
+			return 0, nil
+		}
+		select {
+		case <-p.rdy:
+		case <-ctx.Done():
+		case <-p.rdl.wait():
+		}
+	}
+}
+
+func (p *Pipe) write(ctx context.Context, b []byte) (n int, err error) {
+	select {
+	case p.wsem <- struct{}{}:
+		defer func() { <-p.wsem }()
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-p.wdl.wait():
+		return 0, os.ErrDeadlineExceeded
+	}
+	for {
+		if err = p.wdl.check(ctx); err != nil {
+			return n, err
+		}
+		p.mu.Lock()
+		if p.werr != nil {
+			p.mu.Unlock()
+			return n, io.ErrClosedPipe
+		}
+		if p.rerr != nil {
+			p.mu.Unlock()
+			signal(p.spc)
+			return n, p.rerr
+		}
+		if n == len(b) {
+			p.mu.Unlock()
+			return n, nil
+		}
+		if p.list.Len() < p.list.Cap() {
+			nw, _ := p.list.Write(b[n:])
+			n += nw
+			p.mu.Unlock()
+			signal(p.rdy)
+			continue
+		}
+		p.mu.Unlock()
+		select {
+		case <-p.spc:
+		case <-ctx.Done():
+		case <-p.wdl.wait():
+		}
+	}
+}
+
+func (p *Pipe) closeRead(err error) error {
+	if err == nil {
+		err = io.ErrClosedPipe
+	}
+	p.mu.Lock()
+	if p.rerr == nil {
+		p.rerr = err
+	}
+	p.mu.Unlock()
+	signal(p.rdy)
+	signal(p.spc)
+	return nil
+}
+
+func (p *Pipe) closeWrite(err error) error {
+	if err == nil {
+		err = io.EOF
+	}
+	p.mu.Lock()
+	if p.werr == nil {
+		p.werr = err
+	}
+	p.mu.Unlock()
+	signal(p.rdy)
+	signal(p.spc)
+	return nil
+}
+
+// Read implements the standard Read interface: it reads bytes buffered in the
+// pipe, blocking until at least one byte is available or the write half is
+// closed. If the write half is closed with an error, that error is returned as
+// err once all buffered bytes have been read; otherwise err is io.EOF.
+func (r PipeReader) Read(b []byte) (n int, err error) {
+	if r.p == nil || !r.p.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	return r.p.read(context.Background(), b)
+}
+
+// ReadContext is like Read, but it returns ctx.Err() if ctx is done before any
+// bytes are available.
+func (r PipeReader) ReadContext(ctx context.Context, b []byte) (n int, err error) {
+	if r.p == nil || !r.p.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if ctx == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	return r.p.read(ctx, b)
+}
+
+// SetReadDeadline sets the deadline for all future and pending calls to Read
+// and ReadContext, after which they return os.ErrDeadlineExceeded instead of
+// blocking. A zero value for t means Read will not time out.
+func (r PipeReader) SetReadDeadline(t time.Time) error {
+	if r.p == nil || !r.p.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	r.p.rdl.set(t)
+	return nil
+}
+
+// Close closes the reader; subsequent writes to the write half of the pipe will
+// return the error io.ErrClosedPipe.
+func (r PipeReader) Close() error {
+	return r.CloseWithError(nil)
+}
+
+// CloseWithError closes the reader; subsequent writes to the write half of the
+// pipe will return the error err.
+//
+// CloseWithError never overwrites the previous error if it exists and always
+// returns nil.
+func (r PipeReader) CloseWithError(err error) error {
+	if r.p == nil || !r.p.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	return r.p.closeRead(err)
+}
+
+// Write implements the standard Write interface: it writes bytes to the pipe,
+// blocking until all of b has been buffered or the pipe is closed. If the read
+// half is closed with an error, that error is returned as err; otherwise err is
+// io.ErrClosedPipe.
+func (w PipeWriter) Write(b []byte) (n int, err error) {
+	if w.p == nil || !w.p.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	return w.p.write(context.Background(), b)
+}
+
+// WriteContext is like Write, but it returns ctx.Err() if ctx is done before
+// all of b has been buffered. The number of bytes buffered is returned as n.
+func (w PipeWriter) WriteContext(ctx context.Context, b []byte) (n int, err error) {
+	if w.p == nil || !w.p.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if ctx == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	return w.p.write(ctx, b)
+}
+
+// SetWriteDeadline sets the deadline for all future and pending calls to Write
+// and WriteContext, after which they return os.ErrDeadlineExceeded instead of
+// blocking. A zero value for t means Write will not time out.
+func (w PipeWriter) SetWriteDeadline(t time.Time) error {
+	if w.p == nil || !w.p.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	w.p.wdl.set(t)
+	return nil
+}
+
+// Close closes the writer; subsequent reads from the read half of the pipe will
+// return the remaining buffered bytes and then io.EOF.
+func (w PipeWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+// CloseWithError closes the writer; subsequent reads from the read half of the
+// pipe will return the remaining buffered bytes and then the error err, or
+// io.EOF if err is nil.
+//
+// CloseWithError never overwrites the previous error if it exists and always
+// returns nil.
+func (w PipeWriter) CloseWithError(err error) error {
+	if w.p == nil || !w.p.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	return w.p.closeWrite(err)
+}
+
+// deadline is an abstraction for handling timeouts, equivalent to the unexported
+// type pipeDeadline from package net.
+//
+// The channel returned by wait is closed when the deadline expires, which wakes
+// every goroutine blocked in a select on it. A single timer is shared by all
+// such goroutines, so no timer is created per call.
+type deadline struct {
+	mu     sync.Mutex // guards timer and cancel
+	timer  *time.Timer
+	cancel chan struct{} // must be non-nil when wait is called
+}
+
+// set sets the point in time when the deadline will time out.
+// A timeout event is signaled by closing the channel returned by wait.
+// Once a timeout has occurred, the deadline can be refreshed by specifying a
+// t value in the future.
+//
+// A zero value for t prevents timeout.
+func (d *deadline) set(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer != nil && !d.timer.Stop() {
+		<-d.cancel // Wait for the timer callback to finish and close cancel
+	}
+	d.timer = nil
+
+	// Time is zero, then there is no deadline.
+	closed := d.cancel != nil && isClosedChan(d.cancel)
+	if t.IsZero() {
+		if d.cancel == nil || closed {
+			d.cancel = make(chan struct{})
+		}
+		return
+	}
+
+	// Time in the future, setup a timer to cancel in the future.
+	if dur := time.Until(t); dur > 0 {
+		if d.cancel == nil || closed {
+			d.cancel = make(chan struct{})
+		}
+		cancel := d.cancel
+		d.timer = time.AfterFunc(dur, func() {
+			close(cancel)
+		})
+		return
+	}
+
+	// Time in the past, so close immediately.
+	if d.cancel == nil {
+		d.cancel = make(chan struct{})
+	}
+	if !isClosedChan(d.cancel) {
+		close(d.cancel)
+	}
+}
+
+// wait returns a channel that is closed when the deadline is exceeded.
+func (d *deadline) wait() chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.cancel
+}
+
+// check returns os.ErrDeadlineExceeded if the deadline is exceeded, or
+// ctx.Err() if ctx is done, and otherwise returns nil.
+func (d *deadline) check(ctx context.Context) error {
+	if isClosedChan(d.wait()) {
+		return os.ErrDeadlineExceeded
+	}
+	return ctx.Err()
+}
+
+func isClosedChan(c <-chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
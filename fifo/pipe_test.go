@@ -0,0 +1,222 @@
+package seq
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPipe_Read(t *testing.T) {
+	var p Pipe
+	p.Configure(make([]byte, 7))
+	r, w := p.Reader(), p.Writer()
+	src := bytes.Repeat([]byte("0123456789"), 100)
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < len(src); i += 13 {
+			j := i + 13
+			if j > len(src) {
+				j = len(src)
+			}
+			if n, err := w.Write(src[i:j]); n != j-i || err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- w.Close()
+	}()
+	var dst bytes.Buffer
+	if _, err := io.Copy(&dst, r); err != nil {
+		t.Fatalf("PipeReader.Read() error = %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("PipeWriter.Write() error = %v", err)
+	}
+	if !bytes.Equal(dst.Bytes(), src) {
+		t.Errorf("PipeReader.Read() = %q, want %q", dst.Bytes(), src)
+	}
+	if n, err := r.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+		t.Errorf("PipeReader.Read() = %v, %v, want %v, %v", n, err, 0, io.EOF)
+	}
+}
+
+func TestPipe_Close(t *testing.T) {
+	errTest := errors.New("test")
+	tests := []struct {
+		name    string
+		close   func(r PipeReader, w PipeWriter)
+		wantRd  error
+		wantWr  error
+		written string
+	}{
+		{
+			name:    "writer",
+			close:   func(r PipeReader, w PipeWriter) { w.Close() },
+			wantRd:  io.EOF,
+			wantWr:  io.ErrClosedPipe,
+			written: "ab",
+		},
+		{
+			name:    "writer-error",
+			close:   func(r PipeReader, w PipeWriter) { w.CloseWithError(errTest) },
+			wantRd:  errTest,
+			wantWr:  io.ErrClosedPipe,
+			written: "ab",
+		},
+		{
+			name:   "reader",
+			close:  func(r PipeReader, w PipeWriter) { r.Close() },
+			wantRd: io.ErrClosedPipe,
+			wantWr: io.ErrClosedPipe,
+		},
+		{
+			name:   "reader-error",
+			close:  func(r PipeReader, w PipeWriter) { r.CloseWithError(errTest) },
+			wantRd: io.ErrClosedPipe,
+			wantWr: errTest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Pipe
+			p.Configure(make([]byte, 4))
+			r, w := p.Reader(), p.Writer()
+			w.Write([]byte("ab"))
+			tt.close(r, w)
+			if _, err := w.Write([]byte("c")); err != tt.wantWr {
+				t.Errorf("PipeWriter.Write() error = %v, want %v", err, tt.wantWr)
+			}
+			var got []byte
+			b := make([]byte, 1)
+			for {
+				n, err := r.Read(b)
+				got = append(got, b[:n]...)
+				if err != nil {
+					if err != tt.wantRd {
+						t.Errorf("PipeReader.Read() error = %v, want %v", err, tt.wantRd)
+					}
+					break
+				}
+			}
+			if string(got) != tt.written {
+				t.Errorf("PipeReader.Read() = %q, want %q", got, tt.written)
+			}
+		})
+	}
+}
+
+func TestPipe_Block(t *testing.T) {
+	var p Pipe
+	p.Configure(make([]byte, 2))
+	r, w := p.Reader(), p.Writer()
+	done := make(chan struct{})
+	go func() {
+		// Blocks until the reader drains the pipe, then until it is closed.
+		w.Write([]byte("abcd"))
+		close(done)
+	}()
+	b := make([]byte, 4)
+	n, _ := io.ReadFull(r, b)
+	<-done
+	if string(b[:n]) != "abcd" {
+		t.Errorf("PipeReader.Read() = %q, want %q", b[:n], "abcd")
+	}
+	go func() { w.Close() }()
+	if _, err := r.Read(b); err != io.EOF {
+		t.Errorf("PipeReader.Read() error = %v, want %v", err, io.EOF)
+	}
+}
+
+func TestPipe_ReadContext(t *testing.T) {
+	var p Pipe
+	p.Configure(make([]byte, 2))
+	r := p.Reader()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := r.ReadContext(ctx, make([]byte, 1)); err != context.Canceled {
+		t.Errorf("PipeReader.ReadContext() error = %v, want %v", err, context.Canceled)
+	}
+	p.Writer().Write([]byte("a"))
+	if _, err := r.ReadContext(ctx, make([]byte, 1)); err != context.Canceled {
+		t.Errorf("PipeReader.ReadContext() error = %v, want %v", err, context.Canceled)
+	}
+	if n, err := r.ReadContext(context.Background(), make([]byte, 1)); n != 1 || err != nil {
+		t.Errorf("PipeReader.ReadContext() = %v, %v, want %v, %v", n, err, 1, nil)
+	}
+}
+
+func TestPipe_WriteContext(t *testing.T) {
+	var p Pipe
+	p.Configure(make([]byte, 2))
+	w := p.Writer()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if n, err := w.WriteContext(ctx, []byte("abc")); n != 2 || err != context.DeadlineExceeded {
+		t.Errorf("PipeWriter.WriteContext() = %v, %v, want %v, %v", n, err, 2, context.DeadlineExceeded)
+	}
+}
+
+func TestPipe_SetReadDeadline(t *testing.T) {
+	var p Pipe
+	p.Configure(make([]byte, 2))
+	r, w := p.Reader(), p.Writer()
+	r.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	if _, err := r.Read(make([]byte, 1)); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("PipeReader.Read() error = %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+	// A deadline in the past fails immediately, even if bytes are available.
+	w.Write([]byte("a"))
+	r.SetReadDeadline(time.Now().Add(-time.Second))
+	if _, err := r.Read(make([]byte, 1)); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("PipeReader.Read() error = %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+	r.SetReadDeadline(time.Time{})
+	if n, err := r.Read(make([]byte, 1)); n != 1 || err != nil {
+		t.Errorf("PipeReader.Read() = %v, %v, want %v, %v", n, err, 1, nil)
+	}
+}
+
+func TestPipe_SetWriteDeadline(t *testing.T) {
+	var p Pipe
+	p.Configure(make([]byte, 2))
+	w := p.Writer()
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.Write([]byte("abc"))
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	// Setting the deadline also applies to the pending call to Write.
+	w.SetWriteDeadline(time.Now())
+	if err := <-done; !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("PipeWriter.Write() error = %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+	w.SetWriteDeadline(time.Now().Add(time.Hour))
+	p.Reader().Read(make([]byte, 2))
+	if n, err := w.Write([]byte("de")); n != 2 || err != nil {
+		t.Errorf("PipeWriter.Write() = %v, %v, want %v, %v", n, err, 2, nil)
+	}
+}
+
+func TestPipe_Allocs(t *testing.T) {
+	var p Pipe
+	p.Configure(make([]byte, 16))
+	r, w := p.Reader(), p.Writer()
+	b := make([]byte, 8)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r.SetReadDeadline(time.Now().Add(time.Hour))
+	allocs := testing.AllocsPerRun(100, func() {
+		w.Write(b)
+		r.Read(b)
+		w.WriteContext(ctx, b)
+		r.ReadContext(ctx, b)
+	})
+	if allocs != 0 {
+		t.Errorf("Pipe allocs = %v, want 0", allocs)
+	}
+}
@@ -0,0 +1,132 @@
+package seq
+
+import (
+	"github.com/ardnew/nogc"
+)
+
+// Policy defines the behavior when pushing to a full Queue.
+type Policy uint8
+
+const (
+	Reject    Policy = iota // retain elements in Queue, like List
+	Overwrite               // dequeue elements from Queue, like Ring
+)
+
+// Queue defines a fixed-length first-in, first-out (FIFO) queue of elements of
+// any type T.
+type Queue[T any] struct {
+	Elem  []T
+	capt  uint32
+	head  uint32 // position of first-in element, in range [0, 2*capt)
+	tail  uint32 // position after last-in element, in range [0, 2*capt)
+	mode  mode
+	valid bool
+}
+
+// Configure initializes q using all of p as storage.
+// The initial length of q is 0; any data already in p may be overwritten.
+// The capacity of q is permanently len(p), which must be greater than 0 and no
+// greater than math.MaxInt32.
+// Callers must not modify p after initializing.
+//
+// If policy is Reject, no elements may be added when q is full. If policy is
+// Overwrite, the oldest element is dequeued when adding to a full q.
+func (q *Queue[T]) Configure(p []T, policy Policy) (ok bool) {
+	if q == nil {
+		return false
+	}
+	switch policy {
+	case Reject:
+		q.mode = retain
+	case Overwrite:
+		q.mode = dequeue
+	default:
+		q.valid = false
+		return false
+	}
+	q.Elem = p
+	q.capt = uint32(len(p))
+	q.head = 0
+	q.tail = 0
+	q.valid = len(p) > 0 && len(p) <= maxCapacity
+	return q.valid
+}
+
+// Len returns the number of elements.
+func (q *Queue[T]) Len() int {
+	if q == nil || !q.valid {
+		return 0
+	}
+	return int(distance(q.capt, q.head, q.tail))
+}
+
+// Cap returns the element capacity.
+func (q *Queue[T]) Cap() int {
+	if q == nil || !q.valid {
+		return 0
+	}
+	return int(q.capt)
+}
+
+// Reset sets the number of elements to 0.
+func (q *Queue[T]) Reset() {
+	if q == nil || !q.valid {
+		return
+	}
+	var zero T
+	for i := range q.Elem {
+		q.Elem[i] = zero
+	}
+	q.head = 0
+	q.tail = 0
+}
+
+// Push appends v to q and returns nil.
+// If q is full, a Reject queue returns ErrWriteOverflow, and an Overwrite queue
+// dequeues the oldest element to make room for v.
+func (q *Queue[T]) Push(v T) (err error) {
+	if q == nil || !q.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	if distance(q.capt, q.head, q.tail) >= q.capt {
+		if q.mode == retain {
+			return &nogc.ErrWriteOverflow
+		}
+		// The oldest element's position in the backing array is reused for v.
+		q.head = advance(q.capt, q.head, 1)
+	}
+	q.Elem[index(q.capt, q.tail)] = v
+	q.tail = advance(q.capt, q.tail, 1)
+	return nil
+}
+
+// Pop removes and returns the oldest element from q and a nil error.
+// If q is empty, returns the zero value of T and ErrEmpty.
+//
+// The position of the returned element in the backing array is cleared so that
+// q does not retain any references held by the element.
+func (q *Queue[T]) Pop() (v T, err error) {
+	if q == nil || !q.valid {
+		return v, &nogc.ErrInvalidReceiver
+	}
+	if q.head == q.tail {
+		return v, &nogc.ErrEmpty
+	}
+	var zero T
+	i := index(q.capt, q.head)
+	v, q.Elem[i] = q.Elem[i], zero
+	q.head = advance(q.capt, q.head, 1)
+	return v, nil
+}
+
+// Peek returns the oldest element from q without removing it and a nil error.
+// If q is empty, returns the zero value of T and ErrEmpty.
+func (q *Queue[T]) Peek() (v T, err error) {
+	if q == nil || !q.valid {
+		return v, &nogc.ErrInvalidReceiver
+	}
+	if q.head == q.tail {
+		return v, &nogc.ErrEmpty
+	}
+	return q.Elem[index(q.capt, q.head)], nil
+}
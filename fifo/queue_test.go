@@ -0,0 +1,115 @@
+package seq
+
+import (
+	"testing"
+
+	"github.com/ardnew/nogc"
+)
+
+type sample struct {
+	id  uint16
+	val int32
+}
+
+func TestQueue_Configure(t *testing.T) {
+	type args struct {
+		p      []sample
+		policy Policy
+	}
+	tests := []struct {
+		name   string
+		args   args
+		wantOk bool
+	}{
+		{name: "reject", args: args{p: make([]sample, 4), policy: Reject}, wantOk: true},
+		{name: "overwrite", args: args{p: make([]sample, 4), policy: Overwrite}, wantOk: true},
+		{name: "nil-storage", args: args{p: nil, policy: Reject}, wantOk: false},
+		{name: "empty-storage", args: args{p: []sample{}, policy: Reject}, wantOk: false},
+		{name: "bad-policy", args: args{p: make([]sample, 4), policy: 2}, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var q Queue[sample]
+			if gotOk := q.Configure(tt.args.p, tt.args.policy); gotOk != tt.wantOk {
+				t.Errorf("Queue.Configure() = %v, want %v", gotOk, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestQueue_Push(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		push    []int
+		want    []int
+		wantErr bool
+	}{
+		{name: "reject-space", policy: Reject, push: []int{1, 2}, want: []int{1, 2}},
+		{name: "reject-full", policy: Reject, push: []int{1, 2, 3, 4}, want: []int{1, 2, 3}, wantErr: true},
+		{name: "overwrite-space", policy: Overwrite, push: []int{1, 2}, want: []int{1, 2}},
+		{name: "overwrite-full", policy: Overwrite, push: []int{1, 2, 3, 4, 5}, want: []int{3, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var q Queue[int]
+			q.Configure(make([]int, 3), tt.policy)
+			var err error
+			for _, v := range tt.push {
+				if e := q.Push(v); e != nil {
+					err = e
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Queue.Push() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if q.Len() != len(tt.want) {
+				t.Fatalf("Queue.Len() = %v, want %v", q.Len(), len(tt.want))
+			}
+			for _, w := range tt.want {
+				if got, _ := q.Peek(); got != w {
+					t.Errorf("Queue.Peek() = %v, want %v", got, w)
+				}
+				if got, err := q.Pop(); err != nil || got != w {
+					t.Errorf("Queue.Pop() = %v, %v, want %v", got, err, w)
+				}
+			}
+			if _, err := q.Pop(); err != &nogc.ErrEmpty {
+				t.Errorf("Queue.Pop() error = %v, want %v", err, &nogc.ErrEmpty)
+			}
+		})
+	}
+}
+
+func TestQueue_Pop(t *testing.T) {
+	v := 1
+	var q Queue[*int]
+	q.Configure(make([]*int, 2), Reject)
+	q.Push(&v)
+	if got, err := q.Pop(); err != nil || got != &v {
+		t.Errorf("Queue.Pop() = %v, %v, want %v", got, err, &v)
+	}
+	for i, p := range q.Elem {
+		if p != nil {
+			t.Errorf("Queue.Elem[%d] = %v, want nil", i, p)
+		}
+	}
+}
+
+func TestQueue_Allocs(t *testing.T) {
+	var q Queue[sample]
+	q.Configure(make([]sample, 8), Overwrite)
+	allocs := testing.AllocsPerRun(100, func() {
+		for i := 0; i < 12; i++ {
+			q.Push(sample{id: uint16(i), val: int32(i)})
+		}
+		q.Peek()
+		for q.Len() > 0 {
+			q.Pop()
+		}
+		q.Reset()
+	})
+	if allocs != 0 {
+		t.Errorf("Queue allocs = %v, want 0", allocs)
+	}
+}
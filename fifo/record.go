@@ -0,0 +1,202 @@
+package seq
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/ardnew/nogc"
+)
+
+// RecordList defines a fixed-length queue of variable-length byte records in
+// which no records may be added when the queue is full.
+type RecordList struct{ record }
+
+// RecordRing defines a fixed-length queue of variable-length byte records in
+// which old records are dequeued if a new record is added when the queue is
+// full.
+type RecordRing struct{ record }
+
+// record defines a first-in, first-out (FIFO) queue of byte records.
+//
+// Each record is stored in q as its length, encoded as a uvarint, followed by
+// the bytes of the record. Records are always added and removed whole, so q
+// never holds a partial record.
+type record struct {
+	q     buf // always in retain mode; record handles dequeuing whole records
+	count uint32
+	mode  mode
+}
+
+// maxRecordHeader is the maximum length of an encoded record length.
+const maxRecordHeader = binary.MaxVarintLen32
+
+// Configure initializes l using all of p as storage.
+// The initial length of l is 0; any data already in p may be overwritten.
+// The byte capacity of l is permanently len(p), which must be greater than 0
+// and no greater than math.MaxInt32, and includes the encoded length of each
+// record.
+// Callers must not modify p after initializing.
+func (l *RecordList) Configure(p []byte) (ok bool) {
+	return l.init(p, retain)
+}
+
+// Configure initializes r using all of p as storage.
+// The initial length of r is 0; any data already in p may be overwritten.
+// The byte capacity of r is permanently len(p), which must be greater than 0
+// and no greater than math.MaxInt32, and includes the encoded length of each
+// record.
+// Callers must not modify p after initializing.
+func (r *RecordRing) Configure(p []byte) (ok bool) {
+	return r.init(p, dequeue)
+}
+
+// init initializes the configuration.
+func (r *record) init(p []byte, mode mode) (ok bool) {
+	if r == nil {
+		return false
+	}
+	if len(p) > maxCapacity {
+		p = nil
+	}
+	r.count = 0
+	r.mode = mode
+	r.q.valid = r.q.init(p, uint32(len(p)), retain)
+	return r.q.valid
+}
+
+// Len returns the number of records.
+func (r *record) Len() int {
+	if r == nil || !r.q.valid {
+		return 0
+	}
+	return int(r.count)
+}
+
+// Size returns the number of bytes used by all records, including the encoded
+// length of each record.
+func (r *record) Size() int {
+	if r == nil {
+		return 0
+	}
+	return r.q.Len()
+}
+
+// Cap returns the byte capacity.
+func (r *record) Cap() int {
+	if r == nil {
+		return 0
+	}
+	return r.q.Cap()
+}
+
+// Reset sets the number of records to 0.
+func (r *record) Reset() {
+	if r == nil || !r.q.valid {
+		return
+	}
+	r.q.Reset()
+	r.count = 0
+}
+
+// WriteRecord appends all of p to r as a single record and returns len(p).
+//
+// Records are never partially written. If p and its encoded length together
+// exceed Cap, returns ErrOutOfRange. If there is not enough free space in r, a
+// RecordList returns ErrWriteOverflow, and a RecordRing dequeues the oldest
+// records until there is.
+func (r *record) WriteRecord(p []byte) (n int, err error) {
+	if r == nil || !r.q.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	if len(p) > maxCapacity {
+		return 0, &nogc.ErrOutOfRange
+	}
+	var h [maxRecordHeader]byte
+	nh := binary.PutUvarint(h[:], uint64(len(p)))
+	need := nh + len(p)
+	if need > r.q.Cap() {
+		return 0, &nogc.ErrOutOfRange
+	}
+	for r.q.Cap()-r.q.Len() < need {
+		if r.mode == retain {
+			return 0, &nogc.ErrWriteOverflow
+		}
+		r.discard()
+	}
+	r.q.Write(h[:nh])
+	r.q.Write(p)
+	r.count++
+	return len(p), nil
+}
+
+// ReadRecord removes the oldest record from r, copies it to dst, and returns the
+// number of bytes copied, which is the length of the record if dst can hold it.
+// If r is empty, returns 0, ErrEmpty.
+//
+// The record is removed even if dst is too small to hold it, in which case only
+// the first len(dst) bytes are copied and ReadRecord returns len(dst),
+// io.ErrShortBuffer. Callers can avoid this by first checking PeekRecordLen.
+func (r *record) ReadRecord(dst []byte) (n int, err error) {
+	if r == nil || !r.q.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if dst == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	size, nh, ok := r.header()
+	if !ok {
+		return 0, r.q.empty()
+	}
+	r1, r2 := r.q.peekSpans(nh, size)
+	n = copy(dst, r1)
+	n += copy(dst[n:], r2)
+	if n < int(size) {
+		err = io.ErrShortBuffer
+	}
+	r.discard()
+	return n, err
+}
+
+// PeekRecordLen returns the length of the oldest record in r without removing
+// it.
+// If r is empty, returns 0, ErrEmpty.
+func (r *record) PeekRecordLen() (n int, err error) {
+	if r == nil || !r.q.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	size, _, ok := r.header()
+	if !ok {
+		return 0, r.q.empty()
+	}
+	return int(size), nil
+}
+
+// header returns the length of the oldest record in r and the length of its
+// encoded length. If r is empty, returns ok == false.
+func (r *record) header() (size, nh uint32, ok bool) {
+	if r.count == 0 {
+		return 0, 0, false
+	}
+	var h [maxRecordHeader]byte
+	k, _ := r.q.PeekAt(0, h[:])
+	v, m := binary.Uvarint(h[:k])
+	if m <= 0 {
+		return 0, 0, false
+	}
+	return uint32(v), uint32(m), true
+}
+
+// discard removes the oldest record from r, if any.
+func (r *record) discard() {
+	size, nh, ok := r.header()
+	if !ok {
+		// Nothing can be trusted if the record length is malformed.
+		r.Reset()
+		return
+	}
+	r.q.head = r.q.advance(r.q.head, nh+size)
+	r.count--
+}
@@ -0,0 +1,117 @@
+package seq
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ardnew/nogc"
+)
+
+func TestRecordList_WriteRecord(t *testing.T) {
+	var l RecordList
+	l.Configure(make([]byte, 10))
+	tests := []struct {
+		name    string
+		p       string
+		wantErr error
+		wantLen int
+	}{
+		{name: "first", p: "abc", wantLen: 1},
+		{name: "second", p: "de", wantLen: 2},
+		{name: "overflow", p: "fgh", wantErr: &nogc.ErrWriteOverflow, wantLen: 2},
+		{name: "empty", p: "", wantLen: 3},
+		{name: "too-long", p: "0123456789", wantErr: &nogc.ErrOutOfRange, wantLen: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size := l.Size()
+			_, err := l.WriteRecord([]byte(tt.p))
+			if err != tt.wantErr {
+				t.Errorf("RecordList.WriteRecord() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && l.Size() != size {
+				t.Errorf("RecordList.WriteRecord() partial write, Size() = %v, want %v", l.Size(), size)
+			}
+			if got := l.Len(); got != tt.wantLen {
+				t.Errorf("RecordList.Len() = %v, want %v", got, tt.wantLen)
+			}
+		})
+	}
+	for _, want := range []string{"abc", "de", ""} {
+		p := make([]byte, 4)
+		n, err := l.ReadRecord(p)
+		if err != nil || string(p[:n]) != want {
+			t.Errorf("RecordList.ReadRecord() = %q, %v, want %q, %v", p[:n], err, want, nil)
+		}
+	}
+	if n, err := l.ReadRecord(make([]byte, 4)); n != 0 || err != &nogc.ErrEmpty {
+		t.Errorf("RecordList.ReadRecord() = %v, %v, want %v, %v", n, err, 0, &nogc.ErrEmpty)
+	}
+}
+
+func TestRecordRing_WriteRecord(t *testing.T) {
+	var r RecordRing
+	r.Configure(make([]byte, 11))
+	// Push many records of varying length to evict across several wraps.
+	var want []string
+	for i := 0; i < 50; i++ {
+		p := strings.Repeat(string(rune('a'+i%26)), i%5)
+		if _, err := r.WriteRecord([]byte(p)); err != nil {
+			t.Fatalf("RecordRing.WriteRecord() error = %v", err)
+		}
+		want = append(want, p)
+		size := 0
+		for _, s := range want {
+			size += 1 + len(s)
+		}
+		for size > r.Cap() {
+			size -= 1 + len(want[0])
+			want = want[1:]
+		}
+		if r.Len() != len(want) || r.Size() != size {
+			t.Fatalf("RecordRing Len(), Size() = %v, %v, want %v, %v", r.Len(), r.Size(), len(want), size)
+		}
+	}
+	for _, w := range want {
+		if n, err := r.PeekRecordLen(); n != len(w) || err != nil {
+			t.Errorf("RecordRing.PeekRecordLen() = %v, %v, want %v, %v", n, err, len(w), nil)
+		}
+		p := make([]byte, 4)
+		n, err := r.ReadRecord(p)
+		if err != nil || string(p[:n]) != w {
+			t.Errorf("RecordRing.ReadRecord() = %q, %v, want %q, %v", p[:n], err, w, nil)
+		}
+	}
+}
+
+func Test_record_ReadRecord(t *testing.T) {
+	var l RecordList
+	l.Configure(make([]byte, 8))
+	l.WriteRecord([]byte("abcd"))
+	l.WriteRecord([]byte("e"))
+	p := make([]byte, 2)
+	if n, err := l.ReadRecord(p); n != 2 || err != io.ErrShortBuffer || string(p) != "ab" {
+		t.Errorf("record.ReadRecord() = %q, %v, want %q, %v", p[:n], err, "ab", io.ErrShortBuffer)
+	}
+	if n, err := l.ReadRecord(p); n != 1 || err != nil || p[0] != 'e' {
+		t.Errorf("record.ReadRecord() = %q, %v, want %q, %v", p[:n], err, "e", nil)
+	}
+	if n, err := l.PeekRecordLen(); n != 0 || err != &nogc.ErrEmpty {
+		t.Errorf("record.PeekRecordLen() = %v, %v, want %v, %v", n, err, 0, &nogc.ErrEmpty)
+	}
+}
+
+func Test_record_Allocs(t *testing.T) {
+	var r RecordRing
+	r.Configure(make([]byte, 16))
+	p := []byte("abcdef")
+	allocs := testing.AllocsPerRun(100, func() {
+		r.WriteRecord(p)
+		r.PeekRecordLen()
+		r.ReadRecord(p)
+	})
+	if allocs != 0 {
+		t.Errorf("record allocs = %v, want 0", allocs)
+	}
+}
@@ -0,0 +1,89 @@
+package seq
+
+import (
+	"unicode/utf8"
+
+	"github.com/ardnew/nogc"
+)
+
+// ReadRune reads the next UTF-8 encoded Unicode character from b and returns
+// the rune and its size in bytes. A rune whose encoding wraps around the end of
+// the backing array is decoded without copying b.
+//
+// If only the first bytes of a rune have been written, ReadRune returns
+// ErrEmpty without dequeuing them, so that the rune can be read once the rest
+// of its bytes are written. If b is closed or full, so that no more bytes can be
+// written, or if the encoding is invalid, ReadRune dequeues one byte and returns
+// U+FFFD, 1.
+// If b is empty, returns ErrEmpty, or io.EOF if b is closed.
+func (b *buf) ReadRune() (r rune, size int, err error) {
+	if b == nil || !b.valid {
+		return 0, 0, &nogc.ErrInvalidReceiver
+	}
+	var p [utf8.UTFMax]byte
+	n, _ := b.PeekAt(0, p[:])
+	if n == 0 {
+		return 0, 0, b.empty()
+	}
+	if !utf8.FullRune(p[:n]) && !b.shut && !b.eof && uint32(n) < b.capt {
+		return 0, 0, &nogc.ErrEmpty
+	}
+	r, size = utf8.DecodeRune(p[:n])
+	b.head = b.advance(b.head, uint32(size))
+	b.dequeued(uint32(size))
+	b.rsize = uint8(size)
+	return r, size, nil
+}
+
+// UnreadRune unreads the last rune read by ReadRune, so that it is returned by
+// the next call to ReadRune.
+//
+// If the last method called on b that reads or writes bytes was not ReadRune,
+// returns ErrInvalidUnread.
+func (b *buf) UnreadRune() error {
+	if b == nil || !b.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	if b.rsize == 0 {
+		return &nogc.ErrInvalidUnread
+	}
+	b.rewind(uint32(b.rsize))
+	return nil
+}
+
+// WriteRune appends the UTF-8 encoding of r to b and returns the number of bytes
+// written.
+//
+// Runes are never partially written. If the encoding of r is longer than Cap,
+// returns ErrOutOfRange. If there is not enough free space in b for the encoding
+// of r, a List returns ErrWriteOverflow, and a Ring dequeues the oldest bytes to
+// make room.
+// If b is closed for writing, returns ErrClosed.
+func (b *buf) WriteRune(r rune) (n int, err error) {
+	if b == nil || !b.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	var p [utf8.UTFMax]byte
+	n = utf8.EncodeRune(p[:], r)
+	if b.shut {
+		return 0, &nogc.ErrClosed
+	}
+	if n > int(b.capt) {
+		return 0, &nogc.ErrOutOfRange
+	}
+	if b.mode == retain && n > int(b.capt-b.distance(b.head, b.tail)) {
+		b.enqueued(uint32(n))
+		b.dropped(uint32(n))
+		return 0, &nogc.ErrWriteOverflow
+	}
+	return write(b, p[:n])
+}
+
+// WriteString appends up to len(s) bytes from s to b and returns the number of
+// bytes copied, the same as Write but without converting s to []byte.
+func (b *buf) WriteString(s string) (n int, err error) {
+	if b == nil || !b.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	return write(b, s)
+}
@@ -0,0 +1,178 @@
+package seq
+
+import (
+	"io"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ardnew/nogc"
+)
+
+var (
+	_ nogc.RuneBuffer = (*List)(nil)
+	_ nogc.RuneBuffer = (*Ring)(nil)
+)
+
+func Test_buf_ReadRune(t *testing.T) {
+	type fields struct {
+		Byte []byte
+		capt uint32
+		head uint32
+		tail uint32
+		shut bool
+	}
+	tests := []struct {
+		name     string
+		fields   fields
+		wantR    rune
+		wantSize int
+		wantErr  error
+		wantB    string
+	}{
+		{
+			name:   "ascii",
+			fields: fields{Byte: []byte("ab\x00\x00"), capt: 4, head: 0, tail: 2},
+			wantR:  'a', wantSize: 1, wantB: "b",
+		},
+		{
+			name:   "wrapped",
+			fields: fields{Byte: []byte("\x82\xac!\xe2"), capt: 4, head: 3, tail: 7},
+			wantR:  '€', wantSize: 3, wantB: "!",
+		},
+		{
+			name:   "partial",
+			fields: fields{Byte: []byte("\x82\xac!\xe2"), capt: 4, head: 3, tail: 5},
+			wantR:  0, wantSize: 0, wantErr: &nogc.ErrEmpty, wantB: "\xe2\x82",
+		},
+		{
+			name:   "partial-closed",
+			fields: fields{Byte: []byte("\x82\xac!\xe2"), capt: 4, head: 3, tail: 5, shut: true},
+			wantR:  utf8.RuneError, wantSize: 1, wantB: "\x82",
+		},
+		{
+			name:   "partial-full",
+			fields: fields{Byte: []byte("\x82\xe2"), capt: 2, head: 1, tail: 3},
+			wantR:  utf8.RuneError, wantSize: 1, wantB: "\x82",
+		},
+		{
+			name:   "invalid",
+			fields: fields{Byte: []byte("\xffa"), capt: 2, head: 0, tail: 2},
+			wantR:  utf8.RuneError, wantSize: 1, wantB: "a",
+		},
+		{
+			name:   "empty",
+			fields: fields{Byte: []byte("ab"), capt: 2, head: 1, tail: 1},
+			wantR:  0, wantSize: 0, wantErr: &nogc.ErrEmpty, wantB: "",
+		},
+		{
+			name:   "empty-closed",
+			fields: fields{Byte: []byte("ab"), capt: 2, head: 1, tail: 1, shut: true},
+			wantR:  0, wantSize: 0, wantErr: io.EOF, wantB: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &buf{
+				Byte:  tt.fields.Byte,
+				capt:  tt.fields.capt,
+				head:  tt.fields.head,
+				tail:  tt.fields.tail,
+				shut:  tt.fields.shut,
+				valid: true,
+			}
+			want := queued(b)
+			gotR, gotSize, err := b.ReadRune()
+			if err != tt.wantErr {
+				t.Errorf("buf.ReadRune() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotR != tt.wantR || gotSize != tt.wantSize {
+				t.Errorf("buf.ReadRune() = %q, %v, want %q, %v", gotR, gotSize, tt.wantR, tt.wantSize)
+			}
+			if got := queued(b); got != tt.wantB {
+				t.Errorf("buf.ReadRune() queued = %q, want %q", got, tt.wantB)
+			}
+			if err == nil {
+				if err := b.UnreadRune(); err != nil {
+					t.Errorf("buf.UnreadRune() error = %v", err)
+				}
+				if got := queued(b); got != want {
+					t.Errorf("buf.UnreadRune() queued = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_buf_UnreadRune(t *testing.T) {
+	var l List
+	l.Configure(make([]byte, 8))
+	l.WriteString("é€")
+	if err := l.UnreadRune(); err != &nogc.ErrInvalidUnread {
+		t.Errorf("buf.UnreadRune() error = %v, want %v", err, &nogc.ErrInvalidUnread)
+	}
+	l.ReadRune()
+	l.WriteByte('a')
+	if err := l.UnreadRune(); err != &nogc.ErrInvalidUnread {
+		t.Errorf("buf.UnreadRune() error = %v, want %v", err, &nogc.ErrInvalidUnread)
+	}
+	l.ReadRune()
+	l.UnreadRune()
+	if err := l.UnreadRune(); err != &nogc.ErrInvalidUnread {
+		t.Errorf("buf.UnreadRune() error = %v, want %v", err, &nogc.ErrInvalidUnread)
+	}
+	if r, _, _ := l.ReadRune(); r != '€' {
+		t.Errorf("buf.ReadRune() = %q, want %q", r, '€')
+	}
+}
+
+func Test_buf_WriteRune(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    mode
+		capt    uint32
+		r       rune
+		wantN   int
+		wantErr error
+		wantB   string
+	}{
+		{name: "list", mode: retain, capt: 5, r: 'é', wantN: 2, wantB: "abé"},
+		{name: "list-full", mode: retain, capt: 4, r: '€', wantN: 0, wantErr: &nogc.ErrWriteOverflow, wantB: "ab"},
+		{name: "ring-full", mode: dequeue, capt: 4, r: '€', wantN: 3, wantB: "b€"},
+		{name: "too-long", mode: dequeue, capt: 2, r: '€', wantN: 0, wantErr: &nogc.ErrOutOfRange, wantB: "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &buf{}
+			b.valid = b.init(make([]byte, tt.capt), tt.capt, tt.mode)
+			b.WriteString("ab")
+			gotN, err := b.WriteRune(tt.r)
+			if err != tt.wantErr {
+				t.Errorf("buf.WriteRune() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotN != tt.wantN {
+				t.Errorf("buf.WriteRune() = %v, want %v", gotN, tt.wantN)
+			}
+			if got := queued(b); got != tt.wantB {
+				t.Errorf("buf.WriteRune() queued = %q, want %q", got, tt.wantB)
+			}
+		})
+	}
+}
+
+func Test_buf_WriteString_Allocs(t *testing.T) {
+	var r Ring
+	r.Configure(make([]byte, 16))
+	s := "héllo, wörld"
+	allocs := testing.AllocsPerRun(100, func() {
+		r.WriteString(s)
+		r.WriteRune('€')
+		for {
+			if _, _, err := r.ReadRune(); err != nil {
+				break
+			}
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("buf.WriteString() allocs = %v, want 0", allocs)
+	}
+}
@@ -0,0 +1,162 @@
+package seq
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/ardnew/nogc"
+)
+
+// maxEmptyReads is the number of consecutive reads returning neither bytes nor
+// an error after which Scanner gives up with io.ErrNoProgress.
+const maxEmptyReads = 100
+
+// Scanner reads tokens from an io.Reader, such as a nogc.Buffer, List, or Ring,
+// like bufio.Scanner, except that all bytes are stored in a fixed-length slice
+// of bytes provided by the caller and nothing is allocated while scanning.
+//
+// Unlike bufio.Scanner, scanning may be resumed after the reader is temporarily
+// empty. If Scan returns false and Err returns ErrEmpty, any partial token is
+// retained in storage, and the next call to Scan continues reading from the
+// reader once more bytes are available.
+type Scanner struct {
+	r     io.Reader
+	split bufio.SplitFunc
+	Byte  []byte
+	start int // position of first unscanned byte in Byte
+	end   int // position after last unscanned byte in Byte
+	token []byte
+	empty int  // number of consecutive reads returning no bytes
+	eof   bool // io.EOF was returned from r
+	err   error
+	valid bool
+}
+
+// Configure initializes s to read from r using all of p as storage and split to
+// split tokens. If split is nil, bufio.ScanLines is used.
+// The length of p is the maximum length of a token, which must be greater than
+// 0. Callers must not modify p after initializing.
+func (s *Scanner) Configure(r io.Reader, p []byte, split bufio.SplitFunc) (ok bool) {
+	if s == nil {
+		return false
+	}
+	if split == nil {
+		split = bufio.ScanLines
+	}
+	s.r = r
+	s.split = split
+	s.Byte = p
+	s.start = 0
+	s.end = 0
+	s.token = nil
+	s.empty = 0
+	s.eof = false
+	s.err = nil
+	s.valid = r != nil && len(p) > 0
+	return s.valid
+}
+
+// Scan advances s to the next token, which is then available through Bytes. It
+// returns false when scanning stops, either by reaching the end of input or an
+// error, which is then available through Err.
+//
+// If the reader returns ErrEmpty, Scan returns false, and a later call to Scan
+// resumes scanning. All other errors stop scanning permanently.
+func (s *Scanner) Scan() bool {
+	if s == nil || !s.valid {
+		return false
+	}
+	s.token = nil
+	if s.err == error(&nogc.ErrEmpty) {
+		s.err = nil
+	}
+	if s.err != nil {
+		return false
+	}
+	for {
+		if s.end > s.start || s.eof {
+			adv, tok, err := s.split(s.Byte[s.start:s.end], s.eof)
+			if err != nil {
+				if err == bufio.ErrFinalToken {
+					s.token, s.err = tok, io.EOF
+					return true
+				}
+				s.err = err
+				return false
+			}
+			if adv < 0 {
+				s.err = bufio.ErrNegativeAdvance
+				return false
+			}
+			if adv > s.end-s.start {
+				s.err = bufio.ErrAdvanceTooFar
+				return false
+			}
+			s.start += adv
+			if tok != nil {
+				s.token = tok
+				return true
+			}
+			if adv > 0 {
+				continue
+			}
+		}
+		if s.eof {
+			s.start, s.end = 0, 0
+			s.err = io.EOF
+			return false
+		}
+		// Shift unscanned bytes to the beginning of storage to make room for
+		// reading more bytes, since the split function needs a contiguous token.
+		if s.start > 0 {
+			s.end = copy(s.Byte, s.Byte[s.start:s.end])
+			s.start = 0
+		}
+		if s.end == len(s.Byte) {
+			s.err = bufio.ErrTooLong
+			return false
+		}
+		n, err := s.r.Read(s.Byte[s.end:])
+		if n < 0 || n > len(s.Byte)-s.end {
+			s.err = &nogc.ErrReadOverflow
+			return false
+		}
+		s.end += n
+		switch {
+		case err == io.EOF:
+			s.eof = true
+		case err != nil:
+			s.err = err
+			return false
+		case n == 0:
+			if s.empty++; s.empty >= maxEmptyReads {
+				s.err = io.ErrNoProgress
+				return false
+			}
+			continue
+		}
+		s.empty = 0
+	}
+}
+
+// Bytes returns the most recent token generated by a call to Scan.
+// The returned slice is a view into the storage of s, so it is only valid until
+// the next call to Scan.
+func (s *Scanner) Bytes() []byte {
+	if s == nil || !s.valid {
+		return nil
+	}
+	return s.token
+}
+
+// Err returns the first error that was encountered by s, or ErrEmpty if the
+// reader was temporarily empty, or nil if scanning reached the end of input.
+func (s *Scanner) Err() error {
+	if s == nil || !s.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
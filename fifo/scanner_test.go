@@ -0,0 +1,87 @@
+package seq
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/ardnew/nogc"
+)
+
+func TestScanner_Scan(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		split   bufio.SplitFunc
+		size    int
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "lines",
+			input: "abc\r\nde\n\nf",
+			size:  5,
+			want:  []string{"abc", "de", "", "f"},
+		},
+		{
+			name:  "words",
+			input: "  ab c\t def ",
+			split: bufio.ScanWords,
+			size:  4,
+			want:  []string{"ab", "c", "def"},
+		},
+		{
+			name:    "too-long",
+			input:   "ab\nabcde\n",
+			size:    4,
+			want:    []string{"ab"},
+			wantErr: bufio.ErrTooLong,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l List
+			l.Configure(make([]byte, 3))
+			src := strings.NewReader(tt.input)
+			var s Scanner
+			s.Configure(&l, make([]byte, tt.size), tt.split)
+			var got []string
+			for {
+				if s.Scan() {
+					got = append(got, string(s.Bytes()))
+					continue
+				}
+				if s.Err() != &nogc.ErrEmpty {
+					break
+				}
+				// Refill the List a few bytes at a time, closing it once the
+				// source is exhausted, to resume scanning across many wraps.
+				if n, _ := l.ReadFrom(src); n == 0 {
+					l.CloseWrite()
+				}
+			}
+			if err := s.Err(); err != tt.wantErr {
+				t.Errorf("Scanner.Err() = %v, want %v", err, tt.wantErr)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Scanner.Bytes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanner_Allocs(t *testing.T) {
+	var r Ring
+	r.Configure(make([]byte, 16))
+	var s Scanner
+	s.Configure(&r, make([]byte, 8), nil)
+	line := []byte("abcdef\n")
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Write(line)
+		for s.Scan() {
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Scanner allocs = %v, want 0", allocs)
+	}
+}
@@ -0,0 +1,139 @@
+package seq
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/ardnew/nogc"
+)
+
+// IndexByte returns the offset from the next unread byte of the first instance
+// of c in b, or -1 if c is not present in b.
+func (b *buf) IndexByte(c byte) int {
+	if b == nil || !b.valid {
+		return -1
+	}
+	r1, r2 := b.ReadSpans()
+	if i := bytes.IndexByte(r1, c); i >= 0 {
+		return i
+	}
+	if i := bytes.IndexByte(r2, c); i >= 0 {
+		return len(r1) + i
+	}
+	return -1
+}
+
+// LastIndexByte returns the offset from the next unread byte of the last
+// instance of c in b, or -1 if c is not present in b.
+func (b *buf) LastIndexByte(c byte) int {
+	if b == nil || !b.valid {
+		return -1
+	}
+	r1, r2 := b.ReadSpans()
+	if i := bytes.LastIndexByte(r2, c); i >= 0 {
+		return len(r1) + i
+	}
+	return bytes.LastIndexByte(r1, c)
+}
+
+// Index returns the offset from the next unread byte of the first instance of
+// sep in b, or -1 if sep is not present in b. If sep is empty, returns 0.
+//
+// Instances of sep that wrap around the end of the backing array are found
+// without copying any bytes.
+func (b *buf) Index(sep []byte) int {
+	if b == nil || !b.valid {
+		return -1
+	}
+	r1, r2 := b.ReadSpans()
+	if i := bytes.Index(r1, sep); i >= 0 {
+		return i
+	}
+	if len(r2) == 0 {
+		return -1
+	}
+	// Check each position in the tail of the first region at which sep would
+	// begin in the first region and end in the second region.
+	lo := len(r1) - len(sep) + 1
+	if lo < 0 {
+		lo = 0
+	}
+	for i := lo; i < len(r1); i++ {
+		m := len(r1) - i
+		if len(sep)-m <= len(r2) &&
+			bytes.Equal(r1[i:], sep[:m]) && bytes.Equal(r2[:len(sep)-m], sep[m:]) {
+			return i
+		}
+	}
+	if i := bytes.Index(r2, sep); i >= 0 {
+		return len(r1) + i
+	}
+	return -1
+}
+
+// ReadSlice dequeues all bytes up to and including the first instance of delim
+// in b and returns views of the one or two contiguous regions of the backing
+// array that hold them, in order. The views are only valid until the next call
+// to a method that writes to b.
+//
+// If delim is not present in b and b is closed, ReadSlice dequeues and returns
+// all bytes with io.EOF. If delim is not present and b is full, ReadSlice
+// dequeues and returns all bytes with bufio.ErrBufferFull, since no more bytes
+// can be added. Otherwise, no bytes are dequeued and ReadSlice returns ErrEmpty,
+// and the caller may try again after more bytes have been written.
+func (b *buf) ReadSlice(delim byte) (r1, r2 []byte, err error) {
+	if b == nil || !b.valid {
+		return nil, nil, &nogc.ErrInvalidReceiver
+	}
+	n, err := b.scan(delim)
+	if n == 0 {
+		return nil, nil, err
+	}
+	r1, r2 = b.peekSpans(0, uint32(n))
+	b.head = b.advance(b.head, uint32(n))
+	b.dequeued(uint32(n))
+	return
+}
+
+// ReadBytesInto dequeues bytes from b up to and including the first instance of
+// delim in b, copies them to dst, and returns the number of bytes copied.
+//
+// If dst is too short to hold all bytes up to and including delim, ReadBytesInto
+// copies len(dst) bytes and returns io.ErrShortBuffer; the remaining bytes are
+// returned by the next call. If delim is not present in b, the bytes copied and
+// error returned are the same as ReadSlice.
+func (b *buf) ReadBytesInto(delim byte, dst []byte) (n int, err error) {
+	if b == nil || !b.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if dst == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	n, err = b.scan(delim)
+	if n > len(dst) {
+		n, err = len(dst), io.ErrShortBuffer
+	}
+	r1, r2 := b.peekSpans(0, uint32(n))
+	c := copy(dst, r1)
+	copy(dst[c:], r2)
+	b.head = b.advance(b.head, uint32(n))
+	b.dequeued(uint32(n))
+	return
+}
+
+// scan returns the number of bytes that ReadSlice dequeues from b when reading
+// up to and including delim, and the error it returns.
+func (b *buf) scan(delim byte) (n int, err error) {
+	if i := b.IndexByte(delim); i >= 0 {
+		return i + 1, nil
+	}
+	n = b.Len()
+	switch {
+	case b.shut || b.eof:
+		return n, io.EOF
+	case n == int(b.capt):
+		return n, bufio.ErrBufferFull
+	}
+	return 0, &nogc.ErrEmpty
+}
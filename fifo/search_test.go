@@ -0,0 +1,176 @@
+package seq
+
+import (
+	"bufio"
+	"io"
+	"testing"
+
+	"github.com/ardnew/nogc"
+)
+
+func Test_buf_IndexByte(t *testing.T) {
+	// Queued bytes are "a\r\nbc\r\n", wrapped after the second byte.
+	b := &buf{Byte: []byte("\nbc\r\na\r"), capt: 7, head: 5, tail: 12, valid: true}
+	tests := []struct {
+		name     string
+		c        byte
+		want     int
+		wantLast int
+	}{
+		{name: "first", c: 'a', want: 0, wantLast: 0},
+		{name: "before-wrap", c: '\r', want: 1, wantLast: 5},
+		{name: "after-wrap", c: '\n', want: 2, wantLast: 6},
+		{name: "missing", c: 'z', want: -1, wantLast: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.IndexByte(tt.c); got != tt.want {
+				t.Errorf("buf.IndexByte() = %v, want %v", got, tt.want)
+			}
+			if got := b.LastIndexByte(tt.c); got != tt.wantLast {
+				t.Errorf("buf.LastIndexByte() = %v, want %v", got, tt.wantLast)
+			}
+		})
+	}
+}
+
+func Test_buf_Index(t *testing.T) {
+	// Queued bytes are "a\r\nbc\r\n", wrapped after the second byte.
+	b := &buf{Byte: []byte("\nbc\r\na\r"), capt: 7, head: 5, tail: 12, valid: true}
+	tests := []struct {
+		name string
+		sep  string
+		want int
+	}{
+		{name: "empty", sep: "", want: 0},
+		{name: "first", sep: "a\r", want: 0},
+		{name: "wrapped", sep: "\r\n", want: 1},
+		{name: "wrapped-long", sep: "a\r\nb", want: 0},
+		{name: "second", sep: "c\r\n", want: 4},
+		{name: "all", sep: "a\r\nbc\r\n", want: 0},
+		{name: "too-long", sep: "a\r\nbc\r\n\x00", want: -1},
+		{name: "missing", sep: "\n\n", want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.Index([]byte(tt.sep)); got != tt.want {
+				t.Errorf("buf.Index() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_buf_ReadSlice(t *testing.T) {
+	type fields struct {
+		Byte []byte
+		capt uint32
+		head uint32
+		tail uint32
+		shut bool
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		delim   byte
+		want    string
+		wantB   string
+		wantErr error
+	}{
+		{
+			name:   "contiguous",
+			fields: fields{Byte: []byte("ab\ncd"), capt: 5, head: 0, tail: 5},
+			delim:  '\n',
+			want:   "ab\n", wantB: "cd",
+		},
+		{
+			name:   "wrapped",
+			fields: fields{Byte: []byte("c\nxab"), capt: 5, head: 3, tail: 7},
+			delim:  '\n',
+			want:   "abc\n", wantB: "",
+		},
+		{
+			name:   "missing",
+			fields: fields{Byte: []byte("c\nxab"), capt: 5, head: 3, tail: 6},
+			delim:  '\n',
+			want:   "", wantB: "abc", wantErr: &nogc.ErrEmpty,
+		},
+		{
+			name:   "missing-closed",
+			fields: fields{Byte: []byte("c\nxab"), capt: 5, head: 3, tail: 6, shut: true},
+			delim:  '\n',
+			want:   "abc", wantB: "", wantErr: io.EOF,
+		},
+		{
+			name:   "missing-full",
+			fields: fields{Byte: []byte("cdeab"), capt: 5, head: 3, tail: 8},
+			delim:  '\n',
+			want:   "abcde", wantB: "", wantErr: bufio.ErrBufferFull,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &buf{
+				Byte:  tt.fields.Byte,
+				capt:  tt.fields.capt,
+				head:  tt.fields.head,
+				tail:  tt.fields.tail,
+				shut:  tt.fields.shut,
+				valid: true,
+			}
+			r1, r2, err := b.ReadSlice(tt.delim)
+			if err != tt.wantErr {
+				t.Errorf("buf.ReadSlice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := string(r1) + string(r2); got != tt.want {
+				t.Errorf("buf.ReadSlice() = %q, want %q", got, tt.want)
+			}
+			if got := queued(b); got != tt.wantB {
+				t.Errorf("buf.ReadSlice() queued = %q, want %q", got, tt.wantB)
+			}
+		})
+	}
+}
+
+func Test_buf_ReadBytesInto(t *testing.T) {
+	tests := []struct {
+		name    string
+		dst     []byte
+		want    string
+		wantB   string
+		wantErr error
+	}{
+		{name: "fit", dst: make([]byte, 8), want: "ab\r\n", wantB: "c"},
+		{name: "exact", dst: make([]byte, 4), want: "ab\r\n", wantB: "c"},
+		{name: "short", dst: make([]byte, 3), want: "ab\r", wantB: "\nc", wantErr: io.ErrShortBuffer},
+		{name: "nil", dst: nil, want: "", wantB: "ab\r\nc", wantErr: &nogc.ErrInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &buf{Byte: []byte("\r\ncab"), capt: 5, head: 3, tail: 8, valid: true}
+			gotN, err := b.ReadBytesInto('\n', tt.dst)
+			if err != tt.wantErr {
+				t.Errorf("buf.ReadBytesInto() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := string(tt.dst[:gotN]); got != tt.want {
+				t.Errorf("buf.ReadBytesInto() = %q, want %q", got, tt.want)
+			}
+			if got := queued(b); got != tt.wantB {
+				t.Errorf("buf.ReadBytesInto() queued = %q, want %q", got, tt.wantB)
+			}
+		})
+	}
+}
+
+func Test_buf_Index_Allocs(t *testing.T) {
+	b := &buf{Byte: []byte("\nbc\r\na\r"), capt: 7, head: 5, tail: 12, valid: true}
+	sep := []byte("\r\n")
+	dst := make([]byte, 8)
+	allocs := testing.AllocsPerRun(100, func() {
+		b.Index(sep)
+		b.ReadBytesInto('\n', dst)
+		b.tail = b.advance(b.head, 7)
+	})
+	if allocs != 0 {
+		t.Errorf("buf.Index() allocs = %v, want 0", allocs)
+	}
+}
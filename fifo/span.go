@@ -0,0 +1,114 @@
+package seq
+
+import (
+	"github.com/ardnew/nogc"
+)
+
+// ReadSpans returns views of the one or two contiguous regions of the backing
+// array that together hold all unread bytes in b, in order. The second region
+// is empty if the unread bytes do not wrap around the end of the backing array.
+//
+// No bytes are dequeued. Callers read directly from the views and then call
+// Consume to dequeue the bytes that were used. The views are only valid until
+// the next call to a method that modifies b.
+func (b *buf) ReadSpans() (r1, r2 []byte) {
+	if b == nil || !b.valid {
+		return nil, nil
+	}
+	return b.peekSpans(0, b.distance(b.head, b.tail))
+}
+
+// Linearize rotates the backing array of b in place so that all unread bytes
+// start at index 0, and then returns a single view of them, in order. No bytes
+// are dequeued, and the bytes that may be unread are kept.
+//
+// The rotation reverses regions of the backing array without allocating, so it
+// takes time proportional to Cap, unless the unread bytes already start at index
+// 0. The view is only valid until the next call to a method that modifies b.
+func (b *buf) Linearize() []byte {
+	if b == nil || !b.valid {
+		return nil
+	}
+	n := b.distance(b.head, b.tail)
+	if i := b.index(b.head); i > 0 {
+		rotate(b.Byte[:b.capt], i)
+	}
+	b.head = 0
+	b.tail = n
+	return b.Byte[:n]
+}
+
+// Consume dequeues the first n unread bytes from b, typically after they were
+// read directly from the views returned by ReadSpans.
+//
+// If n is negative or greater than Len, no bytes are dequeued and Consume
+// returns ErrOutOfRange.
+func (b *buf) Consume(n int) (err error) {
+	if b == nil || !b.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	if n < 0 || n > b.Len() {
+		return &nogc.ErrOutOfRange
+	}
+	b.head = b.advance(b.head, uint32(n))
+	b.dequeued(uint32(n))
+	return nil
+}
+
+// WriteSpans returns views of the one or two contiguous regions of the backing
+// array that together form all free space in b, in order. The second region is
+// empty if the free space does not wrap around the end of the backing array.
+//
+// No bytes are enqueued. Callers write directly into the views and then call
+// Commit to enqueue the bytes that were written, which must be a prefix of the
+// first view followed by a prefix of the second view. The views are only valid
+// until the next call to a method that modifies b.
+//
+// The views never include bytes that are already queued, even in a Ring. Use
+// Discard to make room for more bytes in a full Ring. Since the views may
+// include bytes that were just read, those bytes can no longer be unread.
+func (b *buf) WriteSpans() (r1, r2 []byte) {
+	if b == nil || !b.valid {
+		return nil, nil
+	}
+	b.unread = 0
+	b.rsize = 0
+	h, t := b.head, b.tail
+	return spans(b.Byte, b.capt, b.index(t), b.capt-b.distance(h, t))
+}
+
+// Commit enqueues the first n bytes of free space in b, typically after they
+// were written directly into the views returned by WriteSpans.
+//
+// If n is negative or greater than the free space in b, no bytes are enqueued
+// and Commit returns ErrOutOfRange. If b is closed for writing, returns
+// ErrClosed.
+func (b *buf) Commit(n int) (err error) {
+	if b == nil || !b.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	if b.shut {
+		return &nogc.ErrClosed
+	}
+	if n < 0 || n > b.Cap()-b.Len() {
+		return &nogc.ErrOutOfRange
+	}
+	b.tail = b.advance(b.tail, uint32(n))
+	b.enqueued(uint32(n))
+	return nil
+}
+
+// rotate moves the element at index k of p to index 0, preserving the cyclic
+// order of all elements, by reversing p[:k] and p[k:] and then all of p.
+func rotate(p []byte, k uint32) {
+	reverse(p[:k])
+	reverse(p[k:])
+	reverse(p)
+}
+
+// reverse reverses the order of the elements of p.
+func reverse(p []byte) {
+	for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+		p[i], p[j] = p[j], p[i]
+	}
+}
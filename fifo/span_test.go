@@ -0,0 +1,174 @@
+package seq
+
+import (
+	"testing"
+)
+
+func Test_buf_ReadSpans(t *testing.T) {
+	type fields struct {
+		Byte []byte
+		capt uint32
+		head uint32
+		tail uint32
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want1  string
+		want2  string
+	}{
+		{name: "empty", fields: fields{Byte: []byte("abcdef"), capt: 6, head: 3, tail: 3}},
+		{name: "contiguous", fields: fields{Byte: []byte("abcdef"), capt: 6, head: 1, tail: 4}, want1: "bcd"},
+		{name: "to-end", fields: fields{Byte: []byte("abcdef"), capt: 6, head: 3, tail: 6}, want1: "def"},
+		{name: "wrapped", fields: fields{Byte: []byte("abcdef"), capt: 6, head: 4, tail: 8}, want1: "ef", want2: "ab"},
+		{name: "full", fields: fields{Byte: []byte("abcdef"), capt: 6, head: 2, tail: 8}, want1: "cdef", want2: "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &buf{
+				Byte:  tt.fields.Byte,
+				capt:  tt.fields.capt,
+				head:  tt.fields.head,
+				tail:  tt.fields.tail,
+				valid: true,
+			}
+			got1, got2 := b.ReadSpans()
+			if string(got1) != tt.want1 || string(got2) != tt.want2 {
+				t.Errorf("buf.ReadSpans() = %q, %q, want %q, %q", got1, got2, tt.want1, tt.want2)
+			}
+		})
+	}
+}
+
+func Test_buf_Linearize(t *testing.T) {
+	type fields struct {
+		Byte []byte
+		capt uint32
+		head uint32
+		tail uint32
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want   string
+		wantB  string
+	}{
+		{name: "empty", fields: fields{Byte: []byte("abcdef"), capt: 6, head: 3, tail: 3}, want: "", wantB: "defabc"},
+		{name: "start", fields: fields{Byte: []byte("abcdef"), capt: 6, head: 6, tail: 9}, want: "abc", wantB: "abcdef"},
+		{name: "contiguous", fields: fields{Byte: []byte("abcdef"), capt: 6, head: 1, tail: 4}, want: "bcd", wantB: "bcdefa"},
+		{name: "wrapped", fields: fields{Byte: []byte("abcdef"), capt: 6, head: 4, tail: 8}, want: "efab", wantB: "efabcd"},
+		{name: "full", fields: fields{Byte: []byte("abcdefg"), capt: 7, head: 9, tail: 2}, want: "cdefgab", wantB: "cdefgab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &buf{
+				Byte:  tt.fields.Byte,
+				capt:  tt.fields.capt,
+				head:  tt.fields.head,
+				tail:  tt.fields.tail,
+				valid: true,
+			}
+			if got := b.Linearize(); string(got) != tt.want {
+				t.Errorf("buf.Linearize() = %q, want %q", got, tt.want)
+			}
+			if got := queued(b); got != tt.want {
+				t.Errorf("buf.Linearize() queued = %q, want %q", got, tt.want)
+			}
+			if string(b.Byte) != tt.wantB {
+				t.Errorf("buf.Linearize() Byte = %q, want %q", b.Byte, tt.wantB)
+			}
+		})
+	}
+}
+
+func Test_buf_Linearize_Unread(t *testing.T) {
+	var r Ring
+	r.Configure(make([]byte, 5))
+	r.WriteString("abcdefg")
+	r.Read(make([]byte, 2))
+	if got := r.Linearize(); string(got) != "efg" {
+		t.Errorf("buf.Linearize() = %q, want %q", got, "efg")
+	}
+	if n, err := r.Unread(2); n != 2 || err != nil {
+		t.Errorf("buf.Unread() = %v, %v, want %v, %v", n, err, 2, nil)
+	}
+	if got := queued(&r.buf); got != "cdefg" {
+		t.Errorf("buf.Unread() queued = %q, want %q", got, "cdefg")
+	}
+}
+
+func Test_buf_Linearize_Allocs(t *testing.T) {
+	var r Ring
+	r.Configure(make([]byte, 16))
+	allocs := testing.AllocsPerRun(100, func() {
+		r.WriteString("hello, world")
+		r.Linearize()
+	})
+	if allocs != 0 {
+		t.Errorf("buf.Linearize() allocs = %v, want 0", allocs)
+	}
+}
+
+func Test_buf_WriteSpans(t *testing.T) {
+	type fields struct {
+		Byte []byte
+		capt uint32
+		head uint32
+		tail uint32
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		want1  string
+		want2  string
+	}{
+		{name: "empty", fields: fields{Byte: []byte("abcdef"), capt: 6, head: 0, tail: 0}, want1: "abcdef"},
+		{name: "contiguous", fields: fields{Byte: []byte("abcdef"), capt: 6, head: 4, tail: 7}, want1: "bcd"},
+		{name: "wrapped", fields: fields{Byte: []byte("abcdef"), capt: 6, head: 2, tail: 4}, want1: "ef", want2: "ab"},
+		{name: "full", fields: fields{Byte: []byte("abcdef"), capt: 6, head: 2, tail: 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &buf{
+				Byte:  tt.fields.Byte,
+				capt:  tt.fields.capt,
+				head:  tt.fields.head,
+				tail:  tt.fields.tail,
+				valid: true,
+			}
+			got1, got2 := b.WriteSpans()
+			if string(got1) != tt.want1 || string(got2) != tt.want2 {
+				t.Errorf("buf.WriteSpans() = %q, %q, want %q, %q", got1, got2, tt.want1, tt.want2)
+			}
+		})
+	}
+}
+
+func Test_buf_Commit(t *testing.T) {
+	var l List
+	l.Configure(make([]byte, 6))
+	l.Write([]byte("xyzw"))
+	l.Discard(3)
+	r1, r2 := l.WriteSpans()
+	n := copy(r1, "ab")
+	n += copy(r2, "cdefgh")
+	if err := l.Commit(n + 1); err == nil {
+		t.Errorf("List.Commit() error = %v, wantErr %v", err, true)
+	}
+	if err := l.Commit(n); err != nil {
+		t.Errorf("List.Commit() error = %v, wantErr %v", err, false)
+	}
+	if got := queued(&l.buf); got != "wabcde" {
+		t.Errorf("List.Commit() queued = %q, want %q", got, "wabcde")
+	}
+	r1, r2 = l.ReadSpans()
+	if err := l.Consume(len(r1) + len(r2) + 1); err == nil {
+		t.Errorf("List.Consume() error = %v, wantErr %v", err, true)
+	}
+	if err := l.Consume(len(r1)); err != nil {
+		t.Errorf("List.Consume() error = %v, wantErr %v", err, false)
+	}
+	if got := queued(&l.buf); got != string(r2) {
+		t.Errorf("List.Consume() queued = %q, want %q", got, r2)
+	}
+}
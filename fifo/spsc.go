@@ -0,0 +1,689 @@
+package seq
+
+import (
+	"io"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/ardnew/nogc"
+)
+
+// cacheLineSize is the assumed size of a CPU cache line. Fields modified by
+// different goroutines are separated by at least this many bytes to prevent
+// false sharing.
+const cacheLineSize = 64
+
+// SPSC defines a fixed-length queue of bytes in which no bytes may be added
+// when the queue is full, like List, that is safe for concurrent use by exactly
+// one producer goroutine and one consumer goroutine without any locking.
+//
+// The producer may call Write, WriteByte, ReadFrom, and CloseWrite. The consumer
+// may call Read, ReadByte, and WriteTo. Len and Cap may be called from either.
+// All other methods, including Configure and Reset, must not be called
+// concurrently with any other method.
+//
+// See SPSCRing for the equivalent of Ring.
+type SPSC struct {
+	Byte  []byte
+	capt  uint32
+	valid bool
+	_     [cacheLineSize]byte
+	head  uint32 // modified by consumer only, in range [0, 2*capt)
+	_     [cacheLineSize - 4]byte
+	tail  uint32 // modified by producer only, in range [0, 2*capt)
+	shut  uint32 // modified by producer only, nonzero once closed for writing
+	_     [cacheLineSize - 8]byte
+}
+
+// Configure initializes s using all of p as storage.
+// The initial length of s is 0; any data already in p may be overwritten.
+// The capacity of s is permanently len(p), which must be greater than 0 and no
+// greater than math.MaxInt32.
+// Callers must not modify p after initializing.
+func (s *SPSC) Configure(p []byte) (ok bool) {
+	if s == nil {
+		return false
+	}
+	s.Byte = p
+	s.capt = uint32(len(p))
+	atomic.StoreUint32(&s.head, 0)
+	atomic.StoreUint32(&s.tail, 0)
+	atomic.StoreUint32(&s.shut, 0)
+	s.valid = len(p) > 0 && len(p) <= maxCapacity
+	return s.valid
+}
+
+// Len returns the number of bytes.
+//
+// If called concurrently with the producer or consumer, the result is only a
+// snapshot that may be stale by the time it is returned.
+func (s *SPSC) Len() int {
+	if s == nil || !s.valid {
+		return 0
+	}
+	return int(snapshot(s.capt, &s.head, &s.tail, 0))
+}
+
+// snapshot returns the distance from head to tail at a single instant, ignoring
+// the given flag bits of head. The consumer may advance head while tail is being
+// loaded, in which case tail may be more than one lap ahead of the head that was
+// loaded, so head is loaded again until it is unchanged.
+func snapshot(capt uint32, head, tail *uint32, flags uint32) uint32 {
+	for {
+		h := atomic.LoadUint32(head)
+		t := atomic.LoadUint32(tail)
+		if atomic.LoadUint32(head) == h {
+			return distance(capt, h&^flags, t)
+		}
+	}
+}
+
+// Cap returns the byte capacity.
+func (s *SPSC) Cap() int {
+	if s == nil || !s.valid {
+		return 0
+	}
+	return int(s.capt)
+}
+
+// Reset sets the number of bytes to 0.
+func (s *SPSC) Reset() {
+	if s == nil || !s.valid {
+		return
+	}
+	atomic.StoreUint32(&s.head, 0)
+	atomic.StoreUint32(&s.tail, 0)
+}
+
+// CloseWrite closes s for writing; subsequent writes return ErrClosed.
+// Bytes already in s can still be read, after which reads return io.EOF.
+//
+// CloseWrite must only be called by the producer. A closed s is only reopened
+// by calling Configure.
+func (s *SPSC) CloseWrite() error {
+	if s == nil || !s.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	// Release shut after all bytes written, so that a consumer that observes it
+	// also observes the final tail.
+	atomic.StoreUint32(&s.shut, 1)
+	return nil
+}
+
+// closed reports whether s is closed for writing. The consumer must call closed
+// before loading tail, so that it never reports io.EOF while bytes remain.
+func (s *SPSC) closed() bool { return atomic.LoadUint32(&s.shut) != 0 }
+
+// spscEmpty returns the error returned by reads from an SPSC or SPSCRing when
+// it is empty, given whether it was closed before it was found empty.
+func spscEmpty(shut bool) error {
+	if shut {
+		return io.EOF
+	}
+	return &nogc.ErrEmpty
+}
+
+// Read copies up to len(p) unread bytes from s to p and returns the number of
+// bytes copied.
+//
+// If s is empty, returns ErrEmpty, or io.EOF if s is closed. Read also returns
+// io.EOF along with the last bytes read from a closed s.
+//
+// Read must only be called by the consumer.
+func (s *SPSC) Read(p []byte) (n int, err error) {
+	if s == nil || !s.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	// Acquire tail to observe all bytes the producer published before it.
+	shut := s.closed()
+	h, t := s.head, atomic.LoadUint32(&s.tail)
+	ns := distance(s.capt, h, t)
+	if uint32(len(p)) < ns {
+		ns = uint32(len(p))
+	} else if ns == 0 && len(p) > 0 {
+		err = spscEmpty(shut)
+	} else if shut {
+		err = io.EOF
+	}
+	a, b := spans(s.Byte, s.capt, index(s.capt, h), ns)
+	n = copy(p, a)
+	n += copy(p[n:], b)
+	// Release head to hand the bytes just read back to the producer.
+	atomic.StoreUint32(&s.head, advance(s.capt, h, uint32(n)))
+	return
+}
+
+// Write appends up to len(p) bytes from p to s and returns the number of bytes
+// copied.
+//
+// Write will only write to the free space in s and then return ErrWriteOverflow
+// if all of p could not be copied.
+//
+// If s is closed for writing, returns ErrClosed.
+//
+// Write must only be called by the producer.
+func (s *SPSC) Write(p []byte) (n int, err error) {
+	if s == nil || !s.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	if s.closed() {
+		return 0, &nogc.ErrClosed
+	}
+	// Acquire head to ensure the consumer is finished with the free space.
+	h, t := atomic.LoadUint32(&s.head), s.tail
+	nf := s.capt - distance(s.capt, h, t)
+	if uint32(len(p)) < nf {
+		nf = uint32(len(p))
+	}
+	a, b := spans(s.Byte, s.capt, index(s.capt, t), nf)
+	n = copy(a, p)
+	n += copy(b, p[n:])
+	// Release tail to publish the bytes just written to the consumer.
+	atomic.StoreUint32(&s.tail, advance(s.capt, t, uint32(n)))
+	if n < len(p) {
+		err = &nogc.ErrWriteOverflow
+	}
+	return
+}
+
+// ReadByte returns the next unread byte from s and a nil error.
+// If s is empty, returns 0, ErrEmpty, or 0, io.EOF if s is closed.
+//
+// ReadByte must only be called by the consumer.
+func (s *SPSC) ReadByte() (c byte, err error) {
+	if s == nil || !s.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	shut := s.closed()
+	h, t := s.head, atomic.LoadUint32(&s.tail)
+	if h == t {
+		return 0, spscEmpty(shut)
+	}
+	c = s.Byte[index(s.capt, h)]
+	atomic.StoreUint32(&s.head, advance(s.capt, h, 1))
+	return c, nil
+}
+
+// WriteByte appends c to s and returns nil.
+// If s is full, returns ErrWriteOverflow.
+// If s is closed for writing, returns ErrClosed.
+//
+// WriteByte must only be called by the producer.
+func (s *SPSC) WriteByte(c byte) (err error) {
+	if s == nil || !s.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	if s.closed() {
+		return &nogc.ErrClosed
+	}
+	h, t := atomic.LoadUint32(&s.head), s.tail
+	if distance(s.capt, h, t) >= s.capt {
+		return &nogc.ErrWriteOverflow
+	}
+	s.Byte[index(s.capt, t)] = c
+	atomic.StoreUint32(&s.tail, advance(s.capt, t, 1))
+	return nil
+}
+
+// ReadFrom copies bytes from r to s until all bytes have been read, s is full,
+// or an error was encountered. Returns the number of bytes successfully copied.
+//
+// A successful ReadFrom returns err == nil and not err == io.EOF.
+// If s is closed for writing, returns ErrClosed.
+// If s is already full, returns ErrReadOverflow.
+//
+// ReadFrom must only be called by the producer.
+func (s *SPSC) ReadFrom(r io.Reader) (n int64, err error) {
+	if s == nil || !s.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if r == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	if s.closed() {
+		return 0, &nogc.ErrClosed
+	}
+	for {
+		h, t := atomic.LoadUint32(&s.head), s.tail
+		nf := s.capt - distance(s.capt, h, t)
+		if nf == 0 {
+			if n == 0 {
+				err = &nogc.ErrReadOverflow
+			}
+			return
+		}
+		// Read into the first contiguous region of free space only; any second
+		// region is handled by the next iteration once tail has wrapped.
+		a, _ := spans(s.Byte, s.capt, index(s.capt, t), nf)
+		nr, errr := r.Read(a)
+		n += int64(nr)
+		atomic.StoreUint32(&s.tail, advance(s.capt, t, uint32(nr)))
+		if errr != nil {
+			// Catch any attempt to return io.EOF and return nil instead.
+			// See documentation on io.ReaderFrom, and io.Copy.
+			if errr == io.EOF {
+				errr = nil
+			}
+			return n, errr
+		}
+	}
+}
+
+// WriteTo copies bytes from s to w until all bytes have been written or an
+// error was encountered. Returns the number of bytes successfully copied.
+//
+// If s is empty, returns ErrEmpty, unless s is closed, in which case there is
+// nothing more to write and WriteTo returns nil.
+//
+// WriteTo must only be called by the consumer.
+func (s *SPSC) WriteTo(w io.Writer) (n int64, err error) {
+	if s == nil || !s.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if w == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	shut := s.closed()
+	h, t := s.head, atomic.LoadUint32(&s.tail)
+	if h == t {
+		// Buffer is empty, writing zero bytes to w.
+		if shut {
+			return 0, nil
+		}
+		return 0, &nogc.ErrEmpty
+	}
+	a, b := spans(s.Byte, s.capt, index(s.capt, h), distance(s.capt, h, t))
+	for _, p := range [...][]byte{a, b} {
+		if len(p) == 0 {
+			break
+		}
+		nw, errw := w.Write(p)
+		n += int64(nw)
+		atomic.StoreUint32(&s.head, advance(s.capt, h, uint32(n)))
+		if errw != nil {
+			return n, errw
+		}
+		if nw < len(p) {
+			return n, io.ErrShortWrite
+		}
+	}
+	return n, nil
+}
+
+// spscBusy is set in the head of an SPSCRing while the consumer is reading the
+// bytes that follow it, so that the producer does not dequeue or overwrite them.
+const spscBusy = 1 << 31
+
+// maxSPSCRingCapacity is the maximum capacity of an SPSCRing, whose positions
+// must not overlap spscBusy.
+const maxSPSCRingCapacity = spscBusy >> 1
+
+// SPSCRing defines a fixed-length queue of bytes in which old bytes are
+// dequeued if new bytes are added when the queue is full, like Ring, that is
+// safe for concurrent use by exactly one producer goroutine and one consumer
+// goroutine without any locking.
+//
+// The producer may call Write, WriteByte, ReadFrom, and CloseWrite. The consumer
+// may call Read, ReadByte, and WriteTo. Len and Cap may be called from either.
+// All other methods, including Configure and Reset, must not be called
+// concurrently with any other method.
+//
+// Both the producer and the consumer advance head, the producer to dequeue the
+// oldest bytes and the consumer to read them, so each claims it with CAS. The
+// consumer marks head as busy while it copies bytes out, and the producer never
+// overwrites the bytes being copied. If there is not enough room for new bytes
+// without overwriting them, the producer drops the oldest of the new bytes
+// instead, so s briefly retains fewer than Cap of the last bytes written.
+type SPSCRing struct {
+	Byte  []byte
+	capt  uint32
+	valid bool
+	_     [cacheLineSize]byte
+	head  uint32 // modified by both, in range [0, 2*capt), or'd with spscBusy
+	_     [cacheLineSize - 4]byte
+	tail  uint32 // modified by producer only, in range [0, 2*capt)
+	shut  uint32 // modified by producer only, nonzero once closed for writing
+	_     [cacheLineSize - 8]byte
+}
+
+// Configure initializes s using all of p as storage.
+// The initial length of s is 0; any data already in p may be overwritten.
+// The capacity of s is permanently len(p), which must be greater than 0 and no
+// greater than 1<<30.
+// Callers must not modify p after initializing.
+func (s *SPSCRing) Configure(p []byte) (ok bool) {
+	if s == nil {
+		return false
+	}
+	s.Byte = p
+	s.capt = uint32(len(p))
+	atomic.StoreUint32(&s.head, 0)
+	atomic.StoreUint32(&s.tail, 0)
+	atomic.StoreUint32(&s.shut, 0)
+	s.valid = len(p) > 0 && len(p) <= maxSPSCRingCapacity
+	return s.valid
+}
+
+// Len returns the number of bytes, including any being read by the consumer.
+//
+// If called concurrently with the producer or consumer, the result is only a
+// snapshot that may be stale by the time it is returned.
+func (s *SPSCRing) Len() int {
+	if s == nil || !s.valid {
+		return 0
+	}
+	return int(snapshot(s.capt, &s.head, &s.tail, spscBusy))
+}
+
+// Cap returns the byte capacity.
+func (s *SPSCRing) Cap() int {
+	if s == nil || !s.valid {
+		return 0
+	}
+	return int(s.capt)
+}
+
+// Reset sets the number of bytes to 0.
+func (s *SPSCRing) Reset() {
+	if s == nil || !s.valid {
+		return
+	}
+	atomic.StoreUint32(&s.head, 0)
+	atomic.StoreUint32(&s.tail, 0)
+}
+
+// CloseWrite closes s for writing; subsequent writes return ErrClosed.
+// Bytes already in s can still be read, after which reads return io.EOF.
+//
+// CloseWrite must only be called by the producer. A closed s is only reopened
+// by calling Configure.
+func (s *SPSCRing) CloseWrite() error {
+	if s == nil || !s.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	// Release shut after all bytes written, so that a consumer that observes it
+	// also observes the final tail.
+	atomic.StoreUint32(&s.shut, 1)
+	return nil
+}
+
+// closed reports whether s is closed for writing. The consumer must call closed
+// before loading tail, so that it never reports io.EOF while bytes remain.
+func (s *SPSCRing) closed() bool { return atomic.LoadUint32(&s.shut) != 0 }
+
+// claim marks head as busy and returns the position of the first unread byte
+// and the number of unread bytes, none of which can then be dequeued or
+// overwritten by the producer until the consumer calls release.
+//
+// claim must only be called by the consumer.
+func (s *SPSCRing) claim() (h, n uint32) {
+	for {
+		// The CAS only fails if the producer dequeued bytes since head was loaded.
+		h = atomic.LoadUint32(&s.head)
+		if atomic.CompareAndSwapUint32(&s.head, h, h|spscBusy) {
+			break
+		}
+	}
+	// Acquire tail to observe all bytes the producer published before it. Since
+	// head cannot change while busy, tail is at most one lap ahead of it.
+	return h, distance(s.capt, h, atomic.LoadUint32(&s.tail))
+}
+
+// release dequeues the first n bytes claimed by claim and clears busy, handing
+// the bytes just read back to the producer.
+//
+// release must only be called by the consumer.
+func (s *SPSCRing) release(h, n uint32) {
+	atomic.StoreUint32(&s.head, advance(s.capt, h, n))
+}
+
+// reserve makes room for n bytes after tail, dequeuing the oldest bytes as
+// needed, and returns the number of bytes nw that may then be written after
+// tail, of which the first nf were already free space. nw is less than n only if
+// the consumer is reading bytes that would otherwise be overwritten.
+//
+// reserve must only be called by the producer.
+func (s *SPSCRing) reserve(n uint32) (nw, nf uint32) {
+	t := s.tail
+	for {
+		h := atomic.LoadUint32(&s.head)
+		if h&spscBusy != 0 {
+			// The consumer is reading bytes from the start of the queue, which may
+			// not be dequeued; only the free space is available.
+			if nf = s.capt - distance(s.capt, h&^spscBusy, t); n > nf {
+				return nf, nf
+			}
+			return n, nf
+		}
+		if nf = s.capt - distance(s.capt, h, t); n <= nf {
+			return n, nf
+		}
+		// The CAS only fails if the consumer claimed or read bytes since head was
+		// loaded.
+		if atomic.CompareAndSwapUint32(&s.head, h, advance(s.capt, h, n-nf)) {
+			return n, nf
+		}
+	}
+}
+
+// Read copies up to len(p) unread bytes from s to p and returns the number of
+// bytes copied.
+//
+// If s is empty, returns ErrEmpty, or io.EOF if s is closed. Read also returns
+// io.EOF along with the last bytes read from a closed s.
+//
+// Read must only be called by the consumer.
+func (s *SPSCRing) Read(p []byte) (n int, err error) {
+	if s == nil || !s.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	shut := s.closed()
+	h, ns := s.claim()
+	if uint32(len(p)) < ns {
+		ns = uint32(len(p))
+	} else if ns == 0 && len(p) > 0 {
+		err = spscEmpty(shut)
+	} else if shut {
+		err = io.EOF
+	}
+	a, b := spans(s.Byte, s.capt, index(s.capt, h), ns)
+	n = copy(p, a)
+	n += copy(p[n:], b)
+	s.release(h, uint32(n))
+	return
+}
+
+// Write appends all of p to s and returns len(p), dequeuing the oldest bytes in
+// s as needed to make room. If len(p) exceeds the capacity of s, only the last
+// Cap() bytes of p are retained. The oldest bytes of p are also dropped if the
+// consumer is concurrently reading the bytes that would be overwritten.
+//
+// If s is closed for writing, returns ErrClosed.
+//
+// Write must only be called by the producer.
+func (s *SPSCRing) Write(p []byte) (n int, err error) {
+	if s == nil || !s.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if p == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	if s.closed() {
+		return 0, &nogc.ErrClosed
+	}
+	n = len(p)
+	if n > int(s.capt) {
+		p = p[n-int(s.capt):]
+	}
+	nw, _ := s.reserve(uint32(len(p)))
+	p = p[uint32(len(p))-nw:]
+	t := s.tail
+	a, b := spans(s.Byte, s.capt, index(s.capt, t), nw)
+	copy(b, p[copy(a, p):])
+	// Release tail to publish the bytes just written to the consumer.
+	atomic.StoreUint32(&s.tail, advance(s.capt, t, nw))
+	return n, nil
+}
+
+// ReadByte returns the next unread byte from s and a nil error.
+// If s is empty, returns 0, ErrEmpty, or 0, io.EOF if s is closed.
+//
+// ReadByte must only be called by the consumer.
+func (s *SPSCRing) ReadByte() (c byte, err error) {
+	if s == nil || !s.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	shut := s.closed()
+	h, ns := s.claim()
+	if ns == 0 {
+		s.release(h, 0)
+		return 0, spscEmpty(shut)
+	}
+	c = s.Byte[index(s.capt, h)]
+	s.release(h, 1)
+	return c, nil
+}
+
+// WriteByte appends c to s and returns nil, dequeuing the oldest byte in s if
+// s is full. c is dropped if the consumer is concurrently reading every byte
+// in a full s.
+// If s is closed for writing, returns ErrClosed.
+//
+// WriteByte must only be called by the producer.
+func (s *SPSCRing) WriteByte(c byte) (err error) {
+	if s == nil || !s.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	if s.closed() {
+		return &nogc.ErrClosed
+	}
+	if nw, _ := s.reserve(1); nw == 0 {
+		return nil
+	}
+	t := s.tail
+	s.Byte[index(s.capt, t)] = c
+	atomic.StoreUint32(&s.tail, advance(s.capt, t, 1))
+	return nil
+}
+
+// ReadFrom copies bytes from r to s until all bytes have been read or an error
+// was encountered, dequeuing the oldest bytes as needed to make room, so that s
+// retains the last Cap() bytes read from r. Returns the number of bytes
+// successfully copied.
+//
+// A successful ReadFrom returns err == nil and not err == io.EOF.
+// If s is closed for writing, returns ErrClosed.
+//
+// If r implements io.WriterTo, s copies from r by calling r.WriteTo. Otherwise,
+// since r may use all of the space it is given as scratch space, a full s
+// dequeues the oldest bytes before each read from r, like Ring.ReadFrom, and
+// they remain dequeued even if r returns fewer bytes than requested.
+//
+// ReadFrom must only be called by the producer.
+func (s *SPSCRing) ReadFrom(r io.Reader) (n int64, err error) {
+	if s == nil || !s.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if r == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	if s.closed() {
+		return 0, &nogc.ErrClosed
+	}
+	if wt, ok := r.(io.WriterTo); ok {
+		// Let r copy from its own storage with Write, which never needs to dequeue
+		// bytes before they are replaced.
+		return wt.WriteTo(s)
+	}
+	// step is the number of the oldest bytes dequeued for the next read once s is
+	// full, like Ring.ReadFrom.
+	step := uint32(1)
+	for {
+		// Read into the free space up to the end of the backing array, or, if
+		// there is none, into the oldest bytes up to the end of the backing array.
+		t := s.tail
+		it := index(s.capt, t)
+		nr := s.capt - it
+		h := atomic.LoadUint32(&s.head) &^ spscBusy
+		if nf := s.capt - distance(s.capt, h, t); nf > 0 && nf < nr {
+			nr = nf
+		} else if nf == 0 && step < nr {
+			nr = step
+		}
+		nw, nf := s.reserve(nr)
+		if nw == 0 {
+			// The consumer is reading every byte in a full s.
+			runtime.Gosched()
+			continue
+		}
+		nc, errr := r.Read(s.Byte[it : it+nw])
+		n += int64(nc)
+		if nf == 0 {
+			step = nextStep(nw, uint32(nc))
+		}
+		atomic.StoreUint32(&s.tail, advance(s.capt, t, uint32(nc)))
+		if errr != nil {
+			// Catch any attempt to return io.EOF and return nil instead.
+			// See documentation on io.ReaderFrom, and io.Copy.
+			if errr == io.EOF {
+				errr = nil
+			}
+			return n, errr
+		}
+	}
+}
+
+// WriteTo copies bytes from s to w until all bytes have been written or an
+// error was encountered. Returns the number of bytes successfully copied.
+//
+// If s is empty, returns ErrEmpty, unless s is closed, in which case there is
+// nothing more to write and WriteTo returns nil.
+//
+// The bytes are written to w directly from the backing array, so the producer
+// cannot overwrite them until w returns.
+//
+// WriteTo must only be called by the consumer.
+func (s *SPSCRing) WriteTo(w io.Writer) (n int64, err error) {
+	if s == nil || !s.valid {
+		return 0, &nogc.ErrInvalidReceiver
+	}
+	if w == nil {
+		return 0, &nogc.ErrInvalidArgument
+	}
+	shut := s.closed()
+	h, ns := s.claim()
+	if ns == 0 {
+		// Buffer is empty, writing zero bytes to w.
+		s.release(h, 0)
+		if shut {
+			return 0, nil
+		}
+		return 0, &nogc.ErrEmpty
+	}
+	a, b := spans(s.Byte, s.capt, index(s.capt, h), ns)
+	for _, p := range [...][]byte{a, b} {
+		if len(p) == 0 {
+			break
+		}
+		nw, errw := w.Write(p)
+		n += int64(nw)
+		if errw == nil && nw < len(p) {
+			errw = io.ErrShortWrite
+		}
+		if errw != nil {
+			err = errw
+			break
+		}
+	}
+	s.release(h, uint32(n))
+	return n, err
+}
@@ -0,0 +1,386 @@
+package seq
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ardnew/nogc"
+)
+
+func TestSPSC_Write(t *testing.T) {
+	tests := []struct {
+		name    string
+		pre     string // written then read before the test to offset head/tail
+		p       string
+		wantN   int
+		wantR   string
+		wantErr bool
+	}{
+		{name: "empty", p: "", wantN: 0, wantR: ""},
+		{name: "space", p: "abc", wantN: 3, wantR: "abc"},
+		{name: "full", p: "abcdef", wantN: 5, wantR: "abcde", wantErr: true},
+		{name: "wrapped", pre: "xyz", p: "abcde", wantN: 5, wantR: "abcde"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s SPSC
+			s.Configure(make([]byte, 5))
+			s.Write([]byte(tt.pre))
+			s.Read(make([]byte, len(tt.pre)))
+			gotN, err := s.Write([]byte(tt.p))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SPSC.Write() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotN != tt.wantN {
+				t.Errorf("SPSC.Write() = %v, want %v", gotN, tt.wantN)
+			}
+			p := make([]byte, 8)
+			n, _ := s.Read(p)
+			if gotR := string(p[:n]); gotR != tt.wantR {
+				t.Errorf("SPSC.Read() = %q, want %q", gotR, tt.wantR)
+			}
+		})
+	}
+}
+
+func TestSPSC_ReadFrom(t *testing.T) {
+	var s SPSC
+	s.Configure(make([]byte, 5))
+	s.Write([]byte("xyz"))
+	s.Read(make([]byte, 3))
+	if n, err := s.ReadFrom(strings.NewReader("abcdefg")); n != 5 || err != nil {
+		t.Errorf("SPSC.ReadFrom() = %v, %v, want %v, %v", n, err, 5, nil)
+	}
+	if _, err := s.ReadFrom(strings.NewReader("h")); err == nil {
+		t.Errorf("SPSC.ReadFrom() error = %v, wantErr %v", err, true)
+	}
+	var w bytes.Buffer
+	if n, err := s.WriteTo(&w); n != 5 || err != nil || w.String() != "abcde" {
+		t.Errorf("SPSC.WriteTo() = %v, %q, %v, want %v, %q, %v", n, w.String(), err, 5, "abcde", nil)
+	}
+	if _, err := s.WriteTo(&w); err != &nogc.ErrEmpty {
+		t.Errorf("SPSC.WriteTo() error = %v, want %v", err, &nogc.ErrEmpty)
+	}
+}
+
+func TestSPSC_CloseWrite(t *testing.T) {
+	var s SPSC
+	s.Configure(make([]byte, 5))
+	s.Write([]byte("abc"))
+	p := make([]byte, 2)
+	if n, err := s.Read(p); n != 2 || err != nil {
+		t.Errorf("SPSC.Read() = %v, %v, want %v, %v", n, err, 2, nil)
+	}
+	if n, err := s.Read(p); n != 1 || err != nil {
+		t.Errorf("SPSC.Read() = %v, %v, want %v, %v", n, err, 1, nil)
+	}
+	if _, err := s.ReadByte(); err != &nogc.ErrEmpty {
+		t.Errorf("SPSC.ReadByte() error = %v, want %v", err, &nogc.ErrEmpty)
+	}
+	s.Write([]byte("de"))
+	s.CloseWrite()
+	if err := s.WriteByte('f'); err != &nogc.ErrClosed {
+		t.Errorf("SPSC.WriteByte() error = %v, want %v", err, &nogc.ErrClosed)
+	}
+	if n, err := s.Read(p); n != 2 || err != io.EOF {
+		t.Errorf("SPSC.Read() = %v, %v, want %v, %v", n, err, 2, io.EOF)
+	}
+	if _, err := s.ReadByte(); err != io.EOF {
+		t.Errorf("SPSC.ReadByte() error = %v, want %v", err, io.EOF)
+	}
+	var w bytes.Buffer
+	if n, err := s.WriteTo(&w); n != 0 || err != nil {
+		t.Errorf("SPSC.WriteTo() = %v, %v, want %v, %v", n, err, 0, nil)
+	}
+}
+
+func TestSPSC_Concurrent(t *testing.T) {
+	const total = 1 << 16
+	var s SPSC
+	s.Configure(make([]byte, 61))
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		var p [7]byte
+		for i := 0; i < total; {
+			k := 0
+			for ; k < len(p) && i+k < total; k++ {
+				p[k] = byte(i + k)
+			}
+			n, _ := s.Write(p[:k])
+			if n == 0 && s.WriteByte(byte(i)) == nil {
+				n = 1
+			}
+			i += n
+			if n == 0 {
+				runtime.Gosched()
+			}
+		}
+	}()
+	errc := make(chan int, 1)
+	go func() {
+		defer wg.Done()
+		var p [13]byte
+		for i := 0; i < total; {
+			n, _ := s.Read(p[:])
+			for _, c := range p[:n] {
+				if c != byte(i) {
+					errc <- i
+					return
+				}
+				i++
+			}
+			if n == 0 {
+				runtime.Gosched()
+			}
+		}
+	}()
+	wg.Wait()
+	select {
+	case i := <-errc:
+		t.Fatalf("SPSC.Read() out of order at byte %d", i)
+	default:
+	}
+	if s.Len() != 0 {
+		t.Errorf("SPSC.Len() = %v, want %v", s.Len(), 0)
+	}
+}
+
+func TestSPSC_Allocs(t *testing.T) {
+	var s SPSC
+	s.Configure(make([]byte, 16))
+	p := make([]byte, 10)
+	allocs := testing.AllocsPerRun(100, func() {
+		s.Write(p)
+		s.WriteByte(1)
+		s.Read(p)
+		s.ReadByte()
+	})
+	if allocs != 0 {
+		t.Errorf("SPSC allocs = %v, want 0", allocs)
+	}
+}
+
+func TestSPSC_Len(t *testing.T) {
+	const total = 1 << 15
+	var s SPSC
+	s.Configure(make([]byte, 8))
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < total; {
+			if s.WriteByte(byte(i)) == nil {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < total; {
+			if _, err := s.ReadByte(); err == nil {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+	done := make(chan struct{})
+	go func() { wg.Wait(); close(done) }()
+	for {
+		if n := s.Len(); n < 0 || n > s.Cap() {
+			t.Fatalf("SPSC.Len() = %v, want in range [0, %v]", n, s.Cap())
+		}
+		select {
+		case <-done:
+			return
+		default:
+			runtime.Gosched()
+		}
+	}
+}
+
+func TestSPSCRing_Write(t *testing.T) {
+	tests := []struct {
+		name  string
+		pre   string // written then read before the test to offset head/tail
+		p     string
+		wantR string
+	}{
+		{name: "empty", p: "", wantR: "xy"},
+		{name: "space", p: "abc", wantR: "xyabc"},
+		{name: "full", p: "abcdef", wantR: "bcdef"},
+		{name: "too-long", p: "abcdefgh", wantR: "defgh"},
+		{name: "wrapped", pre: "uvw", p: "abcd", wantR: "yabcd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s SPSCRing
+			s.Configure(make([]byte, 5))
+			s.Write([]byte(tt.pre))
+			s.Read(make([]byte, len(tt.pre)))
+			s.Write([]byte("xy"))
+			if n, err := s.Write([]byte(tt.p)); n != len(tt.p) || err != nil {
+				t.Errorf("SPSCRing.Write() = %v, %v, want %v, %v", n, err, len(tt.p), nil)
+			}
+			if s.Len() != len(tt.wantR) {
+				t.Errorf("SPSCRing.Len() = %v, want %v", s.Len(), len(tt.wantR))
+			}
+			p := make([]byte, 8)
+			n, _ := s.Read(p)
+			if gotR := string(p[:n]); gotR != tt.wantR {
+				t.Errorf("SPSCRing.Read() = %q, want %q", gotR, tt.wantR)
+			}
+		})
+	}
+}
+
+func TestSPSCRing_Write_Busy(t *testing.T) {
+	var s SPSCRing
+	s.Configure(make([]byte, 5))
+	s.Write([]byte("abcd"))
+	// While the consumer is reading, the bytes it claimed are not overwritten,
+	// and the oldest of the new bytes are dropped instead.
+	h, n := s.claim()
+	s.Write([]byte("efg"))
+	if err := s.WriteByte('h'); err != nil {
+		t.Errorf("SPSCRing.WriteByte() error = %v", err)
+	}
+	if got := string(s.Byte); got != "abcdg" {
+		t.Errorf("SPSCRing.Write() Byte = %q, want %q", got, "abcdg")
+	}
+	s.release(h, 2)
+	if n != 4 || s.Len() != 3 {
+		t.Errorf("SPSCRing.Len() = %v, want %v", s.Len(), 3)
+	}
+	s.Write([]byte("ijk"))
+	p := make([]byte, 8)
+	m, _ := s.Read(p)
+	if got := string(p[:m]); got != "dgijk" {
+		t.Errorf("SPSCRing.Read() = %q, want %q", got, "dgijk")
+	}
+}
+
+func TestSPSCRing_ReadFrom(t *testing.T) {
+	var s SPSCRing
+	s.Configure(make([]byte, 5))
+	s.Write([]byte("xyz"))
+	s.Read(make([]byte, 3))
+	if n, err := s.ReadFrom(strings.NewReader("abcdefg")); n != 7 || err != nil {
+		t.Errorf("SPSCRing.ReadFrom() = %v, %v, want %v, %v", n, err, 7, nil)
+	}
+	var w bytes.Buffer
+	if n, err := s.WriteTo(&w); n != 5 || err != nil || w.String() != "cdefg" {
+		t.Errorf("SPSCRing.WriteTo() = %v, %q, %v, want %v, %q, %v", n, w.String(), err, 5, "cdefg", nil)
+	}
+	if _, err := s.WriteTo(&w); err != &nogc.ErrEmpty {
+		t.Errorf("SPSCRing.WriteTo() error = %v, want %v", err, &nogc.ErrEmpty)
+	}
+	// A reader that uses all of its buffer as scratch space must not corrupt the
+	// bytes retained in s.
+	for _, tt := range []struct{ init, src, want string }{
+		{init: "abc", src: "X", want: "abcX"},
+		{init: "abcde", src: "XY", want: "deXY"},
+	} {
+		s.Write([]byte(tt.init))
+		if n, err := s.ReadFrom(&scratchReader{s: tt.src}); n != int64(len(tt.src)) || err != nil {
+			t.Errorf("SPSCRing.ReadFrom() = %v, %v, want %v, %v", n, err, len(tt.src), nil)
+		}
+		w.Reset()
+		if s.WriteTo(&w); w.String() != tt.want {
+			t.Errorf("SPSCRing.WriteTo() = %q, want %q", w.String(), tt.want)
+		}
+	}
+	s.Write([]byte("h"))
+	s.CloseWrite()
+	if _, err := s.ReadFrom(strings.NewReader("i")); err != &nogc.ErrClosed {
+		t.Errorf("SPSCRing.ReadFrom() error = %v, want %v", err, &nogc.ErrClosed)
+	}
+	if c, err := s.ReadByte(); c != 'h' || err != nil {
+		t.Errorf("SPSCRing.ReadByte() = %q, %v, want %q, %v", c, err, 'h', nil)
+	}
+	if _, err := s.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("SPSCRing.Read() error = %v, want %v", err, io.EOF)
+	}
+}
+
+func TestSPSCRing_Concurrent(t *testing.T) {
+	// Every write and read is a multiple of 4 bytes, so that bytes are always
+	// dropped in whole records, and each record read must be newer than the last.
+	const total = 1 << 15
+	var s SPSCRing
+	s.Configure(make([]byte, 64))
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		var p [4]byte
+		for i := uint32(1); i <= total; i++ {
+			binary.BigEndian.PutUint32(p[:], i)
+			s.Write(p[:])
+			if i%64 == 0 {
+				runtime.Gosched()
+			}
+		}
+		s.CloseWrite()
+	}()
+	errc := make(chan uint32, 1)
+	var last uint32
+	go func() {
+		defer wg.Done()
+		var p [12]byte
+		for {
+			n, err := s.Read(p[:])
+			for k := 0; k+4 <= n; k += 4 {
+				v := binary.BigEndian.Uint32(p[k:])
+				if v <= last || v > total {
+					errc <- v
+					return
+				}
+				last = v
+			}
+			if err == io.EOF {
+				return
+			}
+			if n == 0 {
+				runtime.Gosched()
+			}
+		}
+	}()
+	wg.Wait()
+	select {
+	case v := <-errc:
+		t.Fatalf("SPSCRing.Read() record %d out of order after %d", v, last)
+	default:
+	}
+	if last != total {
+		t.Errorf("SPSCRing.Read() last record = %v, want %v", last, total)
+	}
+}
+
+func TestSPSCRing_Allocs(t *testing.T) {
+	var s SPSCRing
+	s.Configure(make([]byte, 16))
+	p := make([]byte, 10)
+	var w bytes.Buffer
+	w.Grow(64)
+	allocs := testing.AllocsPerRun(100, func() {
+		s.Write(p)
+		s.WriteByte(1)
+		s.Read(p[:4])
+		s.ReadByte()
+		w.Reset()
+		s.WriteTo(&w)
+	})
+	if allocs != 0 {
+		t.Errorf("SPSCRing allocs = %v, want 0", allocs)
+	}
+}
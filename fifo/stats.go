@@ -0,0 +1,60 @@
+package seq
+
+// Stats contains counters of the bytes that have passed through a List or Ring
+// since it was initialized or the counters were last reset with ResetStats.
+//
+// Unless bytes were discarded by Reset or Close, Written-Read-Dropped equals
+// the change in Len since the counters were last reset. A ReadFrom rejected by a
+// full List counts as an overflow, although it reads and drops no bytes.
+type Stats struct {
+	Written   uint64 // bytes written, including any that were dropped
+	Read      uint64 // bytes read, discarded, or consumed by the caller
+	Dropped   uint64 // bytes rejected by a full List or overwritten in a Ring
+	Overflows uint64 // number of writes that dropped at least one byte
+	HighWater int    // greatest Len observed after a write
+}
+
+// Stats returns the counters of the bytes that have passed through b.
+func (b *buf) Stats() Stats {
+	if b == nil || !b.valid {
+		return Stats{}
+	}
+	return b.stats
+}
+
+// ResetStats sets all counters of b to 0 and sets the high-water mark to the
+// current Len. Bytes in b are not modified.
+func (b *buf) ResetStats() {
+	if b == nil || !b.valid {
+		return
+	}
+	b.stats = Stats{HighWater: b.Len()}
+}
+
+// enqueued records that n bytes were written to b, after the position of tail
+// has been advanced.
+func (b *buf) enqueued(n uint32) {
+	b.stats.Written += uint64(n)
+	if ns := int(b.distance(b.head, b.tail)); ns > b.stats.HighWater {
+		b.stats.HighWater = ns
+	}
+	b.unread = 0
+	b.rsize = 0
+	b.mark()
+}
+
+// dequeued records that n bytes were read from b.
+func (b *buf) dequeued(n uint32) {
+	b.stats.Read += uint64(n)
+	b.unread = n
+	b.rsize = 0
+	b.mark()
+}
+
+// dropped records that n bytes written to b were dropped by a single write.
+func (b *buf) dropped(n uint32) {
+	if n > 0 {
+		b.stats.Dropped += uint64(n)
+		b.stats.Overflows++
+	}
+}
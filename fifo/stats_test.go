@@ -0,0 +1,98 @@
+package seq
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_buf_Stats(t *testing.T) {
+	tests := []struct {
+		name string
+		mode mode
+		ops  func(b *buf)
+		want Stats
+	}{
+		{
+			name: "list-overflow",
+			mode: retain,
+			ops: func(b *buf) {
+				b.Write([]byte("abc"))
+				b.Read(make([]byte, 2))
+				b.Write([]byte("defgh"))
+				b.WriteByte('i')
+			},
+			want: Stats{Written: 9, Read: 2, Dropped: 3, Overflows: 2, HighWater: 4},
+		},
+		{
+			name: "ring-overwrite",
+			mode: dequeue,
+			ops: func(b *buf) {
+				b.Write([]byte("abc"))
+				b.Write([]byte("defghij"))
+				b.WriteByte('k')
+				b.ReadByte()
+				b.UnreadByte()
+				b.Discard(1)
+			},
+			want: Stats{Written: 11, Read: 1, Dropped: 7, Overflows: 2, HighWater: 4},
+		},
+		{
+			name: "list-readfrom-full",
+			mode: retain,
+			ops: func(b *buf) {
+				b.ReadFrom(bytes.NewReader([]byte("abcdef")))
+				b.ReadFrom(bytes.NewReader([]byte("gh")))
+			},
+			want: Stats{Written: 4, Overflows: 1, HighWater: 4},
+		},
+		{
+			name: "ring-readfrom",
+			mode: dequeue,
+			ops: func(b *buf) {
+				b.ReadFrom(bytes.NewReader([]byte("abcdef")))
+				b.WriteTo(&bytes.Buffer{})
+			},
+			want: Stats{Written: 6, Read: 4, Dropped: 2, Overflows: 1, HighWater: 4},
+		},
+		{
+			name: "spans",
+			mode: retain,
+			ops: func(b *buf) {
+				b.Commit(3)
+				b.Consume(2)
+				b.Next(1)
+			},
+			want: Stats{Written: 3, Read: 3, HighWater: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &buf{}
+			b.valid = b.init(make([]byte, 4), 4, tt.mode)
+			tt.ops(b)
+			if got := b.Stats(); got != tt.want {
+				t.Errorf("buf.Stats() = %+v, want %+v", got, tt.want)
+			}
+			// Reset does not reset the counters.
+			b.Reset()
+			if got := b.Stats(); got != tt.want {
+				t.Errorf("buf.Stats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_buf_ResetStats(t *testing.T) {
+	var l List
+	l.Configure(make([]byte, 4))
+	l.Write([]byte("abc"))
+	l.ResetStats()
+	if got, want := l.Stats(), (Stats{HighWater: 3}); got != want {
+		t.Errorf("buf.ResetStats() = %+v, want %+v", got, want)
+	}
+	l.Read(make([]byte, 3))
+	l.Write([]byte("d"))
+	if got, want := l.Stats(), (Stats{Written: 1, Read: 3, HighWater: 3}); got != want {
+		t.Errorf("buf.Stats() = %+v, want %+v", got, want)
+	}
+}
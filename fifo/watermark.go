@@ -0,0 +1,73 @@
+package seq
+
+import (
+	"github.com/ardnew/nogc"
+)
+
+// SetWatermarks configures low and high watermarks on the length of b for flow
+// control. If high is 0, watermarks are disabled. Otherwise, low must be less
+// than high, and high must be no greater than Cap; if not, no watermarks are
+// changed and SetWatermarks returns ErrOutOfRange.
+//
+// Crossings are edge-triggered with hysteresis: b is above its watermarks once
+// Len increases to at least high, and remains above them until Len decreases to
+// at most low. Each change is recorded for Watermark and, if notify is not nil,
+// reported by calling notify with whether b is now above its watermarks.
+//
+// The state is evaluated immediately, so notify is called by SetWatermarks if
+// Len is already at least high. Otherwise, it is evaluated by every method that
+// changes Len, and notify is called synchronously before that method returns.
+// notify must not modify b.
+func (b *buf) SetWatermarks(low, high int, notify func(above bool)) (err error) {
+	if b == nil || !b.valid {
+		return &nogc.ErrInvalidReceiver
+	}
+	if high != 0 && (low < 0 || low >= high || high > int(b.capt)) {
+		return &nogc.ErrOutOfRange
+	}
+	b.low = uint32(low)
+	b.high = uint32(high)
+	b.notify = notify
+	b.above = false
+	b.crossed = false
+	if high == 0 {
+		b.low = 0
+		b.notify = nil
+	}
+	b.mark()
+	return nil
+}
+
+// Watermark returns whether b is above its watermarks, and whether that has
+// changed since the last call to Watermark.
+//
+// Callers that cannot use a callback can poll Watermark instead; for example, to
+// deassert RTS when changed and above are both true.
+func (b *buf) Watermark() (above, changed bool) {
+	if b == nil || !b.valid {
+		return false, false
+	}
+	above, changed = b.above, b.crossed
+	b.crossed = false
+	return
+}
+
+// mark updates whether b is above its watermarks after Len has changed.
+func (b *buf) mark() {
+	if b.high == 0 {
+		return
+	}
+	ns := b.distance(b.head, b.tail)
+	switch {
+	case !b.above && ns >= b.high:
+		b.above = true
+	case b.above && ns <= b.low:
+		b.above = false
+	default:
+		return
+	}
+	b.crossed = true
+	if b.notify != nil {
+		b.notify(b.above)
+	}
+}
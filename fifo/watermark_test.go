@@ -0,0 +1,93 @@
+package seq
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ardnew/nogc"
+)
+
+func Test_buf_SetWatermarks(t *testing.T) {
+	tests := []struct {
+		name    string
+		low     int
+		high    int
+		wantErr error
+	}{
+		{name: "valid", low: 2, high: 6},
+		{name: "full", low: 0, high: 8},
+		{name: "disabled", low: 5, high: 0},
+		{name: "equal", low: 4, high: 4, wantErr: &nogc.ErrOutOfRange},
+		{name: "negative", low: -1, high: 4, wantErr: &nogc.ErrOutOfRange},
+		{name: "capacity", low: 2, high: 9, wantErr: &nogc.ErrOutOfRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l List
+			l.Configure(make([]byte, 8))
+			if err := l.SetWatermarks(tt.low, tt.high, nil); err != tt.wantErr {
+				t.Errorf("buf.SetWatermarks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_buf_Watermark(t *testing.T) {
+	var l List
+	l.Configure(make([]byte, 8))
+	var events []bool
+	l.SetWatermarks(2, 6, func(above bool) { events = append(events, above) })
+	steps := []struct {
+		name        string
+		op          func()
+		wantAbove   bool
+		wantChanged bool
+	}{
+		{name: "below", op: func() { l.Write([]byte("abcde")) }},
+		{name: "high", op: func() { l.WriteByte('f') }, wantAbove: true, wantChanged: true},
+		{name: "hysteresis", op: func() { l.Read(make([]byte, 3)) }, wantAbove: true},
+		{name: "low", op: func() { l.ReadByte() }, wantAbove: false, wantChanged: true},
+		{name: "unread", op: func() { l.UnreadByte() }, wantAbove: false},
+		{name: "readfrom", op: func() { l.ReadFrom(bytes.NewReader([]byte("ghijk"))) }, wantAbove: true, wantChanged: true},
+		{name: "writeto", op: func() { l.WriteTo(&bytes.Buffer{}) }, wantAbove: false, wantChanged: true},
+		{name: "commit", op: func() { l.Commit(7) }, wantAbove: true, wantChanged: true},
+		{name: "reset", op: func() { l.Reset() }, wantAbove: false, wantChanged: true},
+	}
+	var want []bool
+	for _, st := range steps {
+		st.op()
+		above, changed := l.Watermark()
+		if above != st.wantAbove || changed != st.wantChanged {
+			t.Errorf("%s: buf.Watermark() = %v, %v, want %v, %v", st.name, above, changed, st.wantAbove, st.wantChanged)
+		}
+		if st.wantChanged {
+			want = append(want, st.wantAbove)
+		}
+	}
+	if len(events) != len(want) {
+		t.Fatalf("buf.SetWatermarks() notify calls = %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("buf.SetWatermarks() notify calls = %v, want %v", events, want)
+			break
+		}
+	}
+}
+
+func Test_buf_Watermark_Ring(t *testing.T) {
+	var r Ring
+	r.Configure(make([]byte, 4))
+	r.Write([]byte("abcd"))
+	n := 0
+	// Already above the high watermark, so notify is called immediately.
+	r.SetWatermarks(1, 3, func(above bool) { n++ })
+	if above, changed := r.Watermark(); !above || !changed || n != 1 {
+		t.Errorf("buf.Watermark() = %v, %v, notify calls %d, want %v, %v, %d", above, changed, n, true, true, 1)
+	}
+	// Overwriting a full Ring does not change its length.
+	r.Write([]byte("efgh"))
+	if above, changed := r.Watermark(); !above || changed || n != 1 {
+		t.Errorf("buf.Watermark() = %v, %v, notify calls %d, want %v, %v, %d", above, changed, n, true, false, 1)
+	}
+}